@@ -194,13 +194,42 @@ type (
 //	var conv = convgen.Struct[Foo, Bar](mod)
 //
 // To import arbitrary type converters into the namespace, use [ImportFunc] or
-// [ImportFuncErr]. To split default configurations for different kinds of
+// [ImportFuncErr]. To reuse converters declared in another package, use
+// [ImportModule]. To split default configurations for different kinds of
 // converters, use [ForStruct], [ForUnion], or [ForEnum] to qualify options. To
-// register error wrappers, use [ImportErrWrap].
+// register error wrappers, use [ImportErrWrap]. To share options between
+// modules, use [Extend].
 func Module(opts ...moduleOption) module {
 	panic("convgen: not generated")
 }
 
+// Extend makes the module inherit the options of a base module. It must be the
+// first option of [Module]. The module starts with the renaming rules, imported
+// functions, error wrappers, and [ForStruct], [ForUnion], and [ForEnum]
+// qualifiers of the base module, and its own options follow them:
+//
+//	// source:
+//	var (
+//		base = convgen.Module(
+//			convgen.RenameToLower(true, true),
+//			convgen.ImportFunc(strconv.Itoa),
+//			convgen.ImportErrWrap(errtrace.Wrap),
+//		)
+//		api = convgen.Module(convgen.Extend(base),
+//			convgen.ImportFunc(formatID), // overrides strconv.Itoa
+//			convgen.ImportErrWrapReset(), // drops errtrace.Wrap
+//		)
+//	)
+//
+// An imported function overrides the inherited one for the same types instead
+// of being reported as a duplicate. Inherited options can be reset by
+// [RenameReset], [ImportFuncReset], and [ImportErrWrapReset]. Converters
+// declared with the base module are visible in the extending module, but not
+// vice versa.
+func Extend(base module) Option[yes, no, no, no, no] {
+	panic("convgen: not generated")
+}
+
 // Struct directive generates a converter function between two struct types
 // without error:
 //
@@ -456,7 +485,21 @@ func ForEnum(opts ...forOption) Option[yes, no, no, no, no] {
 //
 // Multiple functions with the same signature cannot be registered. For
 // error-returning conversions, use [ImportFuncErr].
-func ImportFunc[In, Out any](fn func(In) Out) Option[yes, no, no, no, no] {
+//
+// When given to a struct or union converter instead of a module, the function
+// is imported only into the converter and the subconverters reached from it.
+// It takes precedence over the functions of the module, so other converters in
+// the module are not affected:
+//
+//	var (
+//		mod        = convgen.Module(convgen.ImportFunc(strconv.Itoa))
+//		EncodeUser = convgen.Struct[User, api.User](mod)
+//		EncodeCode = convgen.Struct[Code, api.Code](mod, convgen.ImportFunc(formatZeroPadded))
+//	)
+//
+// Such a converter does not share subconverters with other converters in the
+// module, because its subconverters may call the function.
+func ImportFunc[In, Out any](fn func(In) Out) Option[yes, no, yes, yes, no] {
 	panic("convgen: not generated")
 }
 
@@ -473,8 +516,154 @@ func ImportFunc[In, Out any](fn func(In) Out) Option[yes, no, no, no, no] {
 //	out.ID, err = strconv.Atoi(in.ID)
 //	// ...
 //
-// Multiple functions with the same signature cannot be registered.
-func ImportFuncErr[In, Out any](fn func(In) (Out, error)) Option[yes, no, no, no, no] {
+// Multiple functions with the same signature cannot be registered. Like
+// [ImportFunc], it can be given to a struct or union converter to import the
+// function only into the converter and the subconverters reached from it.
+func ImportFuncErr[In, Out any](fn func(In) (Out, error)) Option[yes, no, yes, yes, no] {
+	panic("convgen: not generated")
+}
+
+// ImportFuncOk registers a comma-ok conversion function (func(In) (Out, bool))
+// with the module, such as a lookup function. What happens when the function
+// reports false depends on the converter:
+//
+//	// source:
+//	var mod = convgen.Module(convgen.ImportFuncOk(ParseCountry))
+//
+//	// generated: (inside a converter in mod)
+//	...
+//	if v, ok := ParseCountry(in.Country); ok {
+//		out.Country = v
+//	} else {
+//		err = convgenerrors.Wrap("Input.Country", convgenerrors.ErrNotOk) // only in Err converters
+//	}
+//	...
+//
+// In Err converters, it is an error wrapping convgenerrors.ErrNotOk with the
+// field path. In errorless converters, the output is left as the zero value.
+// To assign another value instead, use [ImportFuncOkOr].
+//
+// Multiple functions with the same signature cannot be registered. Like
+// [ImportFunc], it can be given to a struct or union converter to import the
+// function only into the converter and the subconverters reached from it.
+func ImportFuncOk[In, Out any](fn func(In) (Out, bool)) Option[yes, no, yes, yes, no] {
+	panic("convgen: not generated")
+}
+
+// ImportFuncOkOr is the variant of [ImportFuncOk] which assigns the fallback
+// value when the function reports false, in both errorless and Err converters:
+//
+//	// source:
+//	var mod = convgen.Module(convgen.ImportFuncOkOr(ParseCountry, CountryUnknown))
+//
+//	// generated: (inside a converter in mod)
+//	...
+//	if v, ok := ParseCountry(in.Country); ok {
+//		out.Country = v
+//	} else {
+//		out.Country = CountryUnknown
+//	}
+//	...
+//
+// The fallback expression is copied into the generated code as is, so it is
+// evaluated whenever the function reports false.
+func ImportFuncOkOr[In, Out any](fn func(In) (Out, bool), fallback Out) Option[yes, no, yes, yes, no] {
+	panic("convgen: not generated")
+}
+
+// ImportFuncGeneric registers a generic errorless conversion function with the
+// module. Go requires a generic function to be instantiated to be passed, so
+// instantiate it with placeholder type arguments which satisfy its
+// constraints, such as any. The placeholders are ignored. Instead, the
+// function is instantiated for each conversion by inferring the type arguments
+// from the input and output types:
+//
+//	// source:
+//	func Ptr[T any](v T) *T { return &v }
+//
+//	var mod = convgen.Module(convgen.ImportFuncGeneric(Ptr[any]))
+//
+//	// generated: (inside a converter in mod)
+//	...
+//	out.Name = Ptr[string](in.Name)
+//	out.Age = Ptr[int](in.Age)
+//	...
+//
+// Every type parameter must appear in the input or output type. Functions
+// registered by [ImportFunc] and [ImportFuncErr] take precedence over
+// instances of generic functions. It is an error if more than one generic
+// function can be instantiated for the same conversion.
+func ImportFuncGeneric[In, Out any](fn func(In) Out) Option[yes, no, no, no, no] {
+	panic("convgen: not generated")
+}
+
+// ImportFuncGenericErr is the error-returning variant of [ImportFuncGeneric].
+// It registers a generic conversion function (func(In) (Out, error)) with the
+// module:
+//
+//	// source:
+//	func Deref[T any](p *T) (T, error) { ... }
+//
+//	var mod = convgen.Module(convgen.ImportFuncGenericErr(Deref[any]))
+func ImportFuncGenericErr[In, Out any](fn func(In) (Out, error)) Option[yes, no, no, no, no] {
+	panic("convgen: not generated")
+}
+
+// ImportFuncsBySample registers every exported function of the package of the
+// given sample with the module if its signature fits a conversion function:
+// func(In) Out or func(In) (Out, error). The sample is a function, variable, or
+// constant declared in the package, or a value of a type declared in the
+// package:
+//
+//	// source: (package conv)
+//	func FormatID(id int) string { ... }
+//	func ParseID(s string) (int, error) { ... }
+//	func Validate(id int) error { ... }             // not imported: no output
+//	func Decode(b []byte, v *Order) error { ... }   // not imported: output parameter
+//
+//	// source:
+//	var mod = convgen.Module(convgen.ImportFuncsBySample(conv.FormatID))
+//
+// It is equivalent to importing each function by [ImportFunc] or
+// [ImportFuncErr], so functions of the same signature cannot be registered.
+// Generic and variadic functions are not imported. Import generic functions by
+// [ImportFuncGeneric] instead, and functions with an output parameter by
+// [ImportFunc] or [ImportFuncErr].
+func ImportFuncsBySample(sample any) Option[yes, no, no, no, no] {
+	panic("convgen: not generated")
+}
+
+// ImportFuncReset clears all functions previously registered by [ImportFunc],
+// [ImportFuncErr], [ImportFuncOk], [ImportFuncOkOr], [ImportFuncGeneric],
+// [ImportFuncGenericErr], [ImportFuncsBySample], and [ImportModule], including
+// the ones inherited by [Extend].
+func ImportFuncReset() Option[yes, no, no, no, no] {
+	panic("convgen: not generated")
+}
+
+// ImportModule registers the exported explicit converters of a module declared
+// in another package with the module. Converters within the module call them
+// instead of generating new subconverters for the same types:
+//
+//	// source: (package conv)
+//	var (
+//		Mod         = convgen.Module()
+//		EncodeMoney = convgen.Struct[Money, api.Money](Mod)
+//		DecodeMoney = convgen.StructErr[api.Money, Money](Mod)
+//	)
+//
+//	// source:
+//	var mod = convgen.Module(convgen.ImportModule(conv.Mod))
+//
+//	// generated (inside a converter in mod):
+//	// ...
+//	out.Price = conv.EncodeMoney(in.Price)
+//	// ...
+//
+// Errorful converters like DecodeMoney are imported as if by [ImportFuncErr],
+// so only errorful converters within the module can call them. Implicit
+// subconverters of the module are not imported because they are unexported.
+func ImportModule(mod module) Option[yes, no, no, no, no] {
 	panic("convgen: not generated")
 }
 
@@ -2,14 +2,17 @@ package convgen_test
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"go/build"
+	"go/types"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -17,6 +20,7 @@ import (
 
 	convgeninternal "github.com/sublee/convgen/internal/convgen"
 	"github.com/sublee/convgen/pkg/convgenanalysis"
+	"github.com/sublee/convgen/pkg/convgenplugin"
 )
 
 // TestAnalysis tests parsing and building errors using the Go analysis
@@ -57,6 +61,56 @@ func TestAnalysis(t *testing.T) {
 	}
 }
 
+// TestAnalysisFixes tests suggested fixes of the analysis errors. Each Go file
+// in the fixture is compared with its golden file after applying the suggested
+// fixes. The golden file is a txtar archive with one section per fix message.
+//
+// The directory structure of testdata for subtests is as follows:
+//
+//	testdata/
+//	└── fix/
+//	    └── pkg1/
+//	        ├── *.go        // with want comments
+//	        └── *.go.golden // txtar archive of fixed files
+func TestAnalysisFixes(t *testing.T) {
+	ents, err := os.ReadDir(filepath.FromSlash("testdata/fix"))
+	require.NoError(t, err)
+
+	t.Setenv("GOFLAGS", "-tags=convgen")
+
+	for _, ent := range ents {
+		if !ent.IsDir() {
+			continue
+		}
+
+		t.Run(ent.Name(), func(t *testing.T) {
+			t.Parallel()
+			analysistest.RunWithSuggestedFixes(t, "", convgenanalysis.Analyzer, "./testdata/fix/"+ent.Name())
+		})
+	}
+}
+
+// TestAnalysisExplain tests the explanations of converters reported by the
+// analyzer with the -explain flag. The fixtures are in testdata/explain.
+func TestAnalysisExplain(t *testing.T) {
+	ents, err := os.ReadDir(filepath.FromSlash("testdata/explain"))
+	require.NoError(t, err)
+
+	t.Setenv("GOFLAGS", "-tags=convgen")
+	require.NoError(t, convgenanalysis.Analyzer.Flags.Set("explain", "convUser"))
+	t.Cleanup(func() { _ = convgenanalysis.Analyzer.Flags.Set("explain", "") })
+
+	for _, ent := range ents {
+		if !ent.IsDir() {
+			continue
+		}
+
+		t.Run(ent.Name(), func(t *testing.T) {
+			analysistest.Run(t, "", convgenanalysis.Analyzer, "./testdata/explain/"+ent.Name())
+		})
+	}
+}
+
 // TestPrograms tests programs in the testdata directory.
 //
 // The directory structure of testdata for subtests is as follows:
@@ -65,10 +119,13 @@ func TestAnalysis(t *testing.T) {
 //	└── program/
 //	    ├── program1/
 //	    │   ├── main_pkg.txt --- If main_pkg.txt is not present, "main" will be used as the default package name.
+//	    │   ├── convgen_pkgs.txt --- If present, Convgen also runs for these packages.
 //	    │   ├── main/
 //	    │   │   └── main.go
 //	    │   └── want/
-//	    │       └── program_output.txt
+//	    │       ├── program_output.txt
+//	    │       ├── convgen_warning.txt --- If not present, no warning is expected.
+//	    │       └── generated_files.txt --- If present, Convgen runs in split mode and generates these files.
 //	    └── program2/
 //	        ├── main_pkg.txt
 //	        ├── foo/
@@ -108,15 +165,687 @@ func TestPrograms(t *testing.T) {
 	}
 }
 
+func init() {
+	convgenplugin.Register("optional", optionalPlugin{})
+	convgenplugin.Register("decimal", decimalPlugin{})
+}
+
+// isPluginTestType reports whether t is a named type with the given name
+// declared in a program test for plugins.
+func isPluginTestType(t types.Type, name string) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return false
+	}
+	return named.Obj().Name() == name && strings.HasPrefix(named.Obj().Pkg().Path(), "example.com/Plugin")
+}
+
+// optionalPlugin converts Optional[T] to U by converting T to U if the value
+// is valid.
+type optionalPlugin struct{}
+
+func (optionalPlugin) Assign(b convgenplugin.Builder, x, y types.Type) (convgenplugin.Assignment, error) {
+	if !isPluginTestType(x, "Optional") {
+		return nil, convgenplugin.ErrSkip
+	}
+	elem, err := b.Build(types.Unalias(x).(*types.Named).TypeArgs().At(0), y)
+	if err != nil {
+		return nil, err
+	}
+	return optionalAssignment{elem}, nil
+}
+
+type optionalAssignment struct{ elem convgenplugin.Assignment }
+
+func (a optionalAssignment) RequiresErr() bool { return a.elem.RequiresErr() }
+
+func (a optionalAssignment) WriteAssign(w convgenplugin.Writer, varX, varY, varErr string) {
+	w.Printf("if %s.Valid {\n", varX)
+	a.elem.WriteAssign(w, varX+".Value", varY, varErr)
+	w.Printf("}\n")
+}
+
+// decimalPlugin converts Decimal to float64 by strconv.ParseFloat.
+type decimalPlugin struct{}
+
+func (decimalPlugin) Assign(b convgenplugin.Builder, x, y types.Type) (convgenplugin.Assignment, error) {
+	if !isPluginTestType(x, "Decimal") || !types.Identical(y, types.Typ[types.Float64]) {
+		return nil, convgenplugin.ErrSkip
+	}
+	return decimalAssignment{}, nil
+}
+
+type decimalAssignment struct{}
+
+func (decimalAssignment) RequiresErr() bool { return true }
+
+func (decimalAssignment) WriteAssign(w convgenplugin.Writer, varX, varY, varErr string) {
+	strconv := w.Import("strconv", "")
+	varTmpErr := w.Name("err")
+	w.Printf("var %s error\n", varTmpErr)
+	w.Printf("%s, %s = %s.ParseFloat(string(%s), 64)\n", varY, varTmpErr, strconv, varX)
+	w.Printf("if %s != nil {\n%s = %s\n}\n", varTmpErr, varErr, varTmpErr)
+}
+
+// TestOrphans tests that a file generated by Convgen is reported as an orphan
+// once its package no longer has Convgen files, but not if its Convgen files
+// are just not loaded.
+func TestOrphans(t *testing.T) {
+	files := map[string][]byte{
+		"main/main.go": []byte("package main\n\nfunc main() {}\n"),
+		"main/convgen_gen.go": []byte(`//go:build !convgen
+
+// Code generated by github.com/sublee/convgen. DO NOT EDIT.
+package main
+`),
+		"linux/linux.go":   []byte("package linux\n"),
+		"linux/convgen.go": []byte("//go:build convgen && linux && !linux\n\npackage linux\n"),
+		"linux/convgen_gen.go": []byte(`//go:build !convgen
+
+// Code generated by github.com/sublee/convgen. DO NOT EDIT.
+package linux
+`),
+	}
+
+	wd, env := newInlineProgram(t, "Orphans", files)
+	result, err := convgeninternal.Main(t.Context(), convgeninternal.Options{
+		WD:       wd,
+		Env:      env,
+		OutFile:  "convgen_gen.go",
+		Patterns: []string{"./main", "./linux"},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, result.Files)
+	assert.Equal(t, []string{filepath.Join("main", "convgen_gen.go")}, result.Orphans)
+}
+
+// TestOrphansSplit tests that a file generated from a Convgen file in split
+// mode is reported as an orphan only if the Convgen file is removed, not if it
+// is just not loaded.
+func TestOrphansSplit(t *testing.T) {
+	generated := []byte(`//go:build !convgen
+
+// Code generated by github.com/sublee/convgen. DO NOT EDIT.
+package main
+`)
+	files := map[string][]byte{
+		"main/main.go":             fieldConvgenFile("X"),
+		"main/user_test.go":        []byte("//go:build convgen\n\npackage main\n"),
+		"main/user_gen_test.go":    generated, // not loaded without -t
+		"main/convgen_gen_test.go": generated, // not loaded without -t
+		"main/removed_gen.go":      generated, // removed.go does not exist
+		"main/removed_gen_test.go": generated, // removed_test.go does not exist
+	}
+
+	wd, env := newInlineProgram(t, "OrphansSplit", files)
+	result, err := convgeninternal.Main(t.Context(), convgeninternal.Options{
+		WD:       wd,
+		Env:      env,
+		OutFile:  "convgen_gen.go",
+		Split:    true,
+		Patterns: []string{"./main"},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, result.Files, filepath.Join("main", "main_gen.go"))
+	assert.Equal(t, []string{
+		filepath.Join("main", "removed_gen.go"),
+		filepath.Join("main", "removed_gen_test.go"),
+	}, result.Orphans)
+}
+
+// TestSplitOverwrite tests that Convgen does not overwrite a file which is not
+// generated by Convgen, such as a file of another generator.
+func TestSplitOverwrite(t *testing.T) {
+	files := map[string][]byte{
+		"main/main.go":     fieldConvgenFile("X"),
+		"main/main_gen.go": []byte("// Code generated by stringer. DO NOT EDIT.\n\npackage main\n"),
+	}
+
+	wd, env := newInlineProgram(t, "SplitOverwrite", files)
+	_, err := convgeninternal.Main(t.Context(), convgeninternal.Options{
+		WD:       wd,
+		Env:      env,
+		OutFile:  "convgen_gen.go",
+		Split:    true,
+		Patterns: []string{"./main"},
+	})
+	require.EqualError(t, err, "cannot overwrite "+filepath.Join("main", "main_gen.go")+": not generated by Convgen")
+}
+
+// TestWatch tests that the watch mode regenerates a package when its file
+// changes.
+func TestWatch(t *testing.T) {
+	files := map[string][]byte{
+		"main/main.go": fieldConvgenFile("X"),
+	}
+
+	wd, env := newInlineProgram(t, "Watch", files)
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	results := make(chan convgeninternal.Result)
+	done := make(chan error)
+	go func() {
+		done <- convgeninternal.Watch(ctx, convgeninternal.Options{
+			WD:       wd,
+			Env:      env,
+			OutFile:  "convgen_gen.go",
+			Patterns: []string{"./main"},
+		}, 10*time.Millisecond, func(result convgeninternal.Result, err error) {
+			assert.NoError(t, err)
+			results <- result
+		})
+	}()
+
+	out := filepath.Join("main", "convgen_gen.go")
+	result := <-results
+	assert.Contains(t, string(result.Files[out]), "out.X = in.X")
+
+	require.NoError(t, os.WriteFile(filepath.Join(wd, "main", "main.go"), fieldConvgenFile("Long"), 0o644))
+	result = <-results
+	assert.Contains(t, string(result.Files[out]), "out.Long = in.Long")
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+// TestCache tests that unchanged packages are served from the cache.
+func TestCache(t *testing.T) {
+	files := map[string][]byte{
+		"main/main.go": fieldConvgenFile("X"),
+	}
+
+	wd, env := newInlineProgram(t, "Cache", files)
+	opts := convgeninternal.Options{
+		WD:       wd,
+		Env:      env,
+		OutFile:  "convgen_gen.go",
+		Patterns: []string{"./main"},
+		CacheDir: t.TempDir(),
+	}
+	out := filepath.Join("main", "convgen_gen.go")
+
+	miss, err := convgeninternal.Main(t.Context(), opts)
+	require.NoError(t, err)
+	assert.Empty(t, miss.CacheHits)
+	assert.Equal(t, []string{"example.com/Cache/main"}, miss.CacheMisses)
+
+	hit, err := convgeninternal.Main(t.Context(), opts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com/Cache/main"}, hit.CacheHits)
+	assert.Empty(t, hit.CacheMisses)
+	assert.Equal(t, miss.Files, hit.Files)
+
+	require.NoError(t, os.WriteFile(filepath.Join(wd, "main", "main.go"), fieldConvgenFile("Y"), 0o644))
+	changed, err := convgeninternal.Main(t.Context(), opts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com/Cache/main"}, changed.CacheMisses)
+	assert.Contains(t, string(changed.Files[out]), "out.Y = in.Y")
+
+	// The build environment may change the types without changing the files.
+	cgo := "CGO_ENABLED=0"
+	if os.Getenv("CGO_ENABLED") == "0" {
+		cgo = "CGO_ENABLED=1"
+	}
+	opts.Env = append(opts.Env, cgo)
+	envChanged, err := convgeninternal.Main(t.Context(), opts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com/Cache/main"}, envChanged.CacheMisses)
+}
+
+// TestOverlay tests that files in an overlay file are used instead of the files
+// on disk.
+func TestOverlay(t *testing.T) {
+	files := map[string][]byte{
+		"main/main.go": fieldConvgenFile("X"),
+	}
+
+	wd, env := newInlineProgram(t, "Overlay", files)
+
+	unsaved := filepath.Join(t.TempDir(), "main.go")
+	require.NoError(t, os.WriteFile(unsaved, fieldConvgenFile("Y"), 0o644))
+	overlayFile := filepath.Join(t.TempDir(), "overlay.json")
+	require.NoError(t, os.WriteFile(overlayFile, []byte(`{"Replace": {"main/main.go": "`+unsaved+`"}}`), 0o644))
+
+	overlay, err := convgeninternal.ReadOverlay(wd, overlayFile)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{filepath.Join(wd, "main", "main.go"): fieldConvgenFile("Y")}, overlay)
+
+	result, err := convgeninternal.Main(t.Context(), convgeninternal.Options{
+		WD:       wd,
+		Env:      env,
+		OutFile:  "convgen_gen.go",
+		Patterns: []string{"./main"},
+		Overlay:  overlay,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, string(result.Files[filepath.Join("main", "convgen_gen.go")]), "out.Y = in.Y")
+
+	onDisk, err := os.ReadFile(filepath.Join(wd, "main", "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, fieldConvgenFile("X"), onDisk)
+}
+
+// TestExplain tests that Main explains the requested converter.
+func TestExplain(t *testing.T) {
+	files := map[string][]byte{
+		"main/main.go": []byte(`//go:build convgen
+
+package main
+
+import "github.com/sublee/convgen"
+
+var conv = convgen.Struct[struct{ X int32 }, struct{ X int64 }](nil)
+
+func main() {}
+`),
+	}
+
+	wd, env := newInlineProgram(t, "Explain", files)
+	opts := convgeninternal.Options{
+		WD:       wd,
+		Env:      env,
+		OutFile:  "convgen_gen.go",
+		Patterns: []string{"./main"},
+		Explain:  "conv",
+	}
+
+	result, err := convgeninternal.Main(t.Context(), opts)
+	require.NoError(t, err)
+	require.Error(t, result.Infos)
+	assert.Contains(t, normalizeWhitespace(result.Infos.Error()), "ok: X -> X // basic cast to int64")
+
+	opts.Explain = "unknown"
+	_, err = convgeninternal.Main(t.Context(), opts)
+	assert.EqualError(t, err, "no converter named unknown")
+}
+
+// TestManifest tests that a mapping manifest is written next to the output
+// file.
+func TestManifest(t *testing.T) {
+	files := map[string][]byte{
+		"main/main.go": []byte(`//go:build convgen
+
+package main
+
+import (
+	"strconv"
+
+	"github.com/sublee/convgen"
+)
+
+var mod = convgen.Module(convgen.ImportFunc(strconv.Itoa))
+
+type (
+	User        struct{ ID int; Profile Profile; Secret string }
+	Profile     struct{ Name string }
+	UserView    struct{ ID string; Profile ProfileView }
+	ProfileView struct{ Name string }
+)
+
+var conv = convgen.Struct[User, UserView](mod,
+	convgen.MatchSkip(User{}.Secret, nil),
+)
+
+func main() {}
+`),
+	}
+
+	wd, env := newInlineProgram(t, "Manifest", files)
+	opts := convgeninternal.Options{
+		WD:       wd,
+		Env:      env,
+		OutFile:  "convgen_gen.go",
+		Patterns: []string{"./main"},
+		Manifest: "json",
+	}
+
+	result, err := convgeninternal.Main(t.Context(), opts)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"package": "example.com/Manifest/main",
+		"converters": [
+			{
+				"name": "conv", "x": "User", "y": "UserView", "kind": "struct", "pos": "main.go:20:12",
+				"pairs": [
+					{"x": "User.ID", "y": "UserView.ID", "via": "function strconv.Itoa"},
+					{"x": "User.Profile", "y": "UserView.Profile", "via": "subconverter convgen_mod_Profile_ProfileView"},
+					{"x": "User.Secret", "skipped": true, "reason": "skipped missing", "at": "main.go:21:2"}
+				],
+				"funcs": ["strconv.Itoa"]
+			},
+			{
+				"name": "convgen_mod_Profile_ProfileView", "x": "Profile", "y": "ProfileView", "kind": "struct", "implicit": true,
+				"pairs": [
+					{"x": "Profile.Name", "y": "ProfileView.Name", "via": "assignment"}
+				]
+			}
+		]
+	}`, string(result.Files["main/convgen_gen_manifest.json"]))
+
+	opts.Manifest = "yaml"
+	result, err = convgeninternal.Main(t.Context(), opts)
+	require.NoError(t, err)
+	assert.Contains(t, string(result.Files["main/convgen_gen_manifest.yaml"]), "reason: skipped missing\n")
+}
+
+// TestGraph tests the dependency graph of converters.
+func TestGraph(t *testing.T) {
+	files := map[string][]byte{
+		"main/main.go": []byte(`//go:build convgen
+
+package main
+
+import (
+	"strconv"
+
+	"github.com/sublee/convgen"
+)
+
+var mod = convgen.Module(convgen.ImportFuncErr(strconv.Atoi))
+
+type (
+	X   struct{ Child XX }
+	Y   struct{ Child YY }
+	XX  struct{ Child XXX; Atoi string }
+	YY  struct{ Child YYY; Atoi int }
+	XXX struct{ Parent *XX }
+	YYY struct{ Parent *YY }
+)
+
+var (
+	conv1 = convgen.StructErr[X, Y](mod)
+	conv2 = convgen.StructErr[XXX, YYY](mod)
+)
+
+func main() {}
+`),
+	}
+
+	wd, env := newInlineProgram(t, "Graph", files)
+	opts := convgeninternal.Options{
+		WD:       wd,
+		Env:      env,
+		OutFile:  "convgen_gen.go",
+		Patterns: []string{"./main"},
+		Graph:    "json",
+	}
+
+	// conv2 reuses the subconverter created by conv1, which calls conv2 back.
+	// The subconverter returns an error because conv2 does.
+	result, err := convgeninternal.Main(t.Context(), opts)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{
+		"package": "example.com/Graph/main",
+		"nodes": [
+			{"id": "conv1", "kind": "converter", "x": "X", "y": "Y", "pos": "main.go:23:10", "err": true},
+			{"id": "conv2", "kind": "converter", "x": "XXX", "y": "YYY", "pos": "main.go:24:10", "err": true, "cyclic": true},
+			{
+				"id": "convgen_mod_XX_YY", "kind": "subconverter", "x": "XX", "y": "YY", "err": true, "cyclic": true,
+				"createdBy": "conv1", "errPath": ["convgen_mod_XX_YY", "conv2"]
+			},
+			{"id": "strconv.Atoi", "kind": "function", "err": true}
+		],
+		"edges": [
+			{"from": "conv1", "to": "convgen_mod_XX_YY", "x": "X.Child", "y": "Y.Child"},
+			{"from": "conv2", "to": "convgen_mod_XX_YY", "x": "XXX.Parent", "y": "YYY.Parent", "reused": true, "cyclic": true},
+			{"from": "convgen_mod_XX_YY", "to": "conv2", "x": "XX.Child", "y": "YY.Child", "cyclic": true, "errPath": true},
+			{"from": "convgen_mod_XX_YY", "to": "strconv.Atoi", "x": "XX.Atoi", "y": "YY.Atoi"}
+		]
+	}]`, string(result.Graph))
+
+	opts.Graph = "dot"
+	result, err = convgeninternal.Main(t.Context(), opts)
+	require.NoError(t, err)
+	dot := string(result.Graph)
+	assert.True(t, strings.HasPrefix(dot, "digraph convgen {\n"))
+	assert.Contains(t, dot, `"example.com/Graph/main.conv2" -> "example.com/Graph/main.convgen_mod_XX_YY" [label="XXX.Parent -> YYY.Parent\n(reused)", style=dashed, color=red];`)
+	assert.Contains(t, dot, `"example.com/Graph/main.convgen_mod_XX_YY" -> "example.com/Graph/main.strconv.Atoi" [label="XX.Atoi -> YY.Atoi"];`)
+}
+
+// TestRoundTrip tests that the generated round-trip tests pass for lossless
+// converters, fail for lossy ones, and skip for inconvertible ones.
+func TestRoundTrip(t *testing.T) {
+	files := map[string][]byte{
+		"main/main.go": []byte(`//go:build convgen
+
+package main
+
+import (
+	"strconv"
+
+	"github.com/sublee/convgen"
+)
+
+type (
+	Status     int
+	StatusView string
+)
+
+const (
+	StatusUnknown Status = iota
+	StatusActive
+	StatusBanned
+)
+
+const (
+	StatusViewUnknown StatusView = ""
+	StatusViewActive  StatusView = "active"
+	StatusViewBanned  StatusView = "banned"
+)
+
+type (
+	User     struct{ ID int; Name string; Status Status; Tags []Tag; Profile *Profile; Password string }
+	Tag      struct{ Name string }
+	Profile  struct{ Age int }
+	UserView struct{ ID string; Name string; Status StatusView; Tags []TagView; Profile *ProfileView }
+	TagView  struct{ Name string }
+	ProfileView struct{ Age int }
+)
+
+var mod = convgen.Module(convgen.ImportFunc(strconv.Itoa), convgen.ImportFuncErr(strconv.Atoi))
+
+var (
+	EncodeUser = convgen.Struct[User, UserView](mod,
+		convgen.MatchSkip(User{}.Password, nil),
+	)
+	DecodeUser = convgen.StructErr[UserView, User](mod,
+		convgen.MatchSkip(nil, User{}.Password),
+	)
+	EncodeStatus = convgen.Enum[Status, StatusView](mod, StatusViewUnknown, convgen.RenameTrimPrefix("Status", "StatusView"))
+	DecodeStatus = convgen.Enum[StatusView, Status](mod, StatusUnknown, convgen.RenameTrimPrefix("StatusView", "Status"))
+)
+
+func main() {}
+`),
+		"lossy/lossy.go": []byte(`//go:build convgen
+
+package lossy
+
+import "github.com/sublee/convgen"
+
+type (
+	X struct{ N int64 }
+	Y struct{ N int8 }
+)
+
+var mod = convgen.Module(convgen.ImportFunc(func(n int64) int8 { return int8(n / 2) }))
+
+var (
+	XtoY = convgen.Struct[X, Y](mod)
+	YtoX = convgen.Struct[Y, X](mod)
+)
+`),
+		"never/never.go": []byte(`//go:build convgen
+
+package never
+
+import (
+	"errors"
+
+	"github.com/sublee/convgen"
+)
+
+type (
+	X struct{ N int }
+	Y struct{ N int }
+)
+
+var mod = convgen.Module(convgen.ImportFuncErr(func(int) (int, error) { return 0, errors.New("never") }))
+
+var (
+	XtoY = convgen.StructErr[X, Y](mod)
+	YtoX = convgen.Struct[Y, X](nil)
+)
+`),
+	}
+
+	wd, env := newInlineProgram(t, "RoundTrip", files)
+	result, err := convgeninternal.Main(t.Context(), convgeninternal.Options{
+		WD:        wd,
+		Env:       env,
+		OutFile:   "convgen_gen.go",
+		Patterns:  []string{"./main", "./lossy", "./never"},
+		RoundTrip: true,
+	})
+	require.NoError(t, err)
+	require.Contains(t, result.Files, "main/convgen_gen_test.go")
+	require.Contains(t, result.Files, "lossy/convgen_gen_test.go")
+	for name, content := range result.Files {
+		require.NoError(t, os.WriteFile(filepath.Join(wd, name), content, 0o666))
+	}
+
+	goCmd := filepath.Join(build.Default.GOROOT, "bin", "go")
+	cmd := exec.Command(goCmd, "test", "./main")
+	cmd.Dir = wd
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	cmd = exec.Command(goCmd, "test", "./lossy")
+	cmd.Dir = wd
+	out, err = cmd.CombinedOutput()
+	require.Error(t, err)
+	assert.Contains(t, string(out), "YtoX(XtoY(x)) != x")
+
+	cmd = exec.Command(goCmd, "test", "-v", "./never")
+	cmd.Dir = wd
+	out, err = cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	assert.Contains(t, string(out), "no random input is convertible by XtoY")
+}
+
+// TestJobs tests that building packages concurrently generates the same files
+// as building them one by one.
+func TestJobs(t *testing.T) {
+	files := map[string][]byte{
+		"x/model/model.go": []byte("package model\n\ntype User struct{ Name string }\n"),
+		"y/model/model.go": []byte("package model\n\ntype User struct{ Name string }\n"),
+	}
+
+	// Packages import two packages with the same name. The second one should
+	// be aliased in each package independently.
+	var patterns []string
+	for i := range 8 {
+		name := fmt.Sprintf("p%d", i)
+		files[name+"/"+name+".go"] = []byte(`//go:build convgen
+
+package ` + name + `
+
+import (
+	"github.com/sublee/convgen"
+
+	xmodel "example.com/Jobs/x/model"
+	ymodel "example.com/Jobs/y/model"
+)
+
+var Conv = convgen.Struct[xmodel.User, ymodel.User](nil)
+`)
+		patterns = append(patterns, "./"+name)
+	}
+
+	wd, env := newInlineProgram(t, "Jobs", files)
+	opts := convgeninternal.Options{
+		WD:       wd,
+		Env:      env,
+		OutFile:  "convgen_gen.go",
+		Patterns: patterns,
+		Jobs:     1,
+	}
+	serial, err := convgeninternal.Main(t.Context(), opts)
+	require.NoError(t, err)
+	assert.Len(t, serial.Files, 8)
+	assert.Contains(t, string(serial.Files[filepath.Join("p0", "convgen_gen.go")]), `model2 "example.com/Jobs/y/model"`)
+
+	opts.Jobs = 8
+	concurrent, err := convgeninternal.Main(t.Context(), opts)
+	require.NoError(t, err)
+	assert.Equal(t, serial.Files, concurrent.Files)
+}
+
+// TestBuildContext tests that the build context to select the files of
+// imported packages follows the environment and the build tags.
+func TestBuildContext(t *testing.T) {
+	env := []string{"GOOS=linux", "GOOS=windows", "GOARCH=arm64", "CGO_ENABLED=0", "GOFLAGS=-mod=mod -tags=foo,bar"}
+	ctxt := convgeninternal.BuildContext(env, "baz")
+	assert.Equal(t, "windows", ctxt.GOOS)
+	assert.Equal(t, "arm64", ctxt.GOARCH)
+	assert.False(t, ctxt.CgoEnabled)
+	assert.Subset(t, ctxt.BuildTags, []string{"convgen", "foo", "bar", "baz"})
+}
+
+// newInlineProgram materializes a program of the files in a temporary GOPATH
+// with Convgen. The names of the files are relative to example.com/<name>. It
+// returns the directory of example.com/<name> and the environment to load it.
+func newInlineProgram(t *testing.T, name string, files map[string][]byte) (string, []string) {
+	t.Helper()
+
+	convgenGo, err := os.ReadFile("convgen.go")
+	require.NoError(t, err)
+	convgenErrorsGo, err := os.ReadFile(filepath.FromSlash("pkg/convgenerrors/errors.go"))
+	require.NoError(t, err)
+
+	test := &programTest{name: name, files: map[string][]byte{
+		"github.com/sublee/convgen/convgen.go":                  convgenGo,
+		"github.com/sublee/convgen/pkg/convgenerrors/errors.go": convgenErrorsGo,
+	}}
+	for file, content := range files {
+		test.files[test.PkgPath()+"/"+file] = content
+	}
+
+	gopath := t.TempDir()
+	require.NoError(t, test.materialize(gopath))
+
+	wd := filepath.Join(gopath, "src", filepath.FromSlash(test.PkgPath()))
+	return wd, append(os.Environ(), "GOPATH="+gopath)
+}
+
+// fieldConvgenFile returns a Convgen file of a main package which converts a
+// struct with the field to the same struct.
+func fieldConvgenFile(field string) []byte {
+	return []byte(`//go:build convgen
+
+package main
+
+import "github.com/sublee/convgen"
+
+var conv = convgen.Struct[struct{ ` + field + ` int }, struct{ ` + field + ` int }](nil)
+
+func main() {}
+`)
+}
+
 // programTest is a test case for a program. It executes Convgen for the program
 // and runs the program with generated code to check the output.
 type programTest struct {
 	name    string
 	mainPkg string
+	pkgs    []string
 	files   map[string][]byte
 	want    struct {
-		ProgramOutput string
-		ConvgenError  string
+		ProgramOutput  string
+		ConvgenError   string
+		ConvgenWarning string
+		GeneratedFiles []string
 	}
 }
 
@@ -149,11 +878,22 @@ func newProgramTest(name string, convgenGo, convgenErrorsGo []byte) (*programTes
 	}
 	test.mainPkg = string(bytes.TrimSpace(mainPkg))
 
+	// pkgs
+	pkgs, err := os.ReadFile(filepath.Join(root, "convgen_pkgs.txt"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("load test case %s: %v", name, err)
+	}
+	test.pkgs = strings.Fields(string(pkgs))
+
 	// want
 	programOutput, _ := os.ReadFile(filepath.Join(root, "want", "program_output.txt"))
 	convgenError, _ := os.ReadFile(filepath.Join(root, "want", "convgen_error.txt"))
 	test.want.ProgramOutput = string(bytes.TrimSpace(programOutput))
 	test.want.ConvgenError = string(bytes.TrimSpace(convgenError))
+	convgenWarning, _ := os.ReadFile(filepath.Join(root, "want", "convgen_warning.txt"))
+	test.want.ConvgenWarning = string(bytes.TrimSpace(convgenWarning))
+	generatedFiles, _ := os.ReadFile(filepath.Join(root, "want", "generated_files.txt"))
+	test.want.GeneratedFiles = strings.Fields(string(generatedFiles))
 
 	if test.want.ProgramOutput == "" && test.want.ConvgenError == "" {
 		return nil, fmt.Errorf("load test case %s: does not want anything", name)
@@ -181,7 +921,7 @@ func newProgramTest(name string, convgenGo, convgenErrorsGo []byte) (*programTes
 			return nil
 		}
 
-		if filepath.Base(path) == "convgen_gen.go" {
+		if filepath.Base(path) == "convgen_gen.go" || strings.HasSuffix(path, "_gen.go") {
 			// Skip generated Convgen files, they might be existed for debugging
 			// purposes.
 			return nil
@@ -259,7 +999,17 @@ func (test *programTest) Test() func(*testing.T) {
 		// Run Convgen
 		wd := filepath.Join(gopath, "src", filepath.FromSlash(test.PkgPath()))
 		env := append(os.Environ(), "GOPATH="+gopath)
-		generated, convgenErr := convgeninternal.Main(t.Context(), wd, env, "", false, "convgen_gen.go", []string{"pattern=./" + test.mainPkg})
+		patterns := []string{"pattern=./" + test.mainPkg}
+		for _, pkg := range test.pkgs {
+			patterns = append(patterns, "pattern=./"+pkg)
+		}
+		result, convgenErr := convgeninternal.Main(t.Context(), convgeninternal.Options{
+			WD:       wd,
+			Env:      env,
+			OutFile:  "convgen_gen.go",
+			Split:    len(test.want.GeneratedFiles) != 0,
+			Patterns: patterns,
+		})
 
 		// Check for the Convgen error
 		if convgenErr != nil {
@@ -278,8 +1028,26 @@ func (test *programTest) Test() func(*testing.T) {
 			require.Error(t, convgenErr, "Convgen should have exited with an error")
 		}
 
+		// Check for the Convgen warning
+		if result.Warnings != nil {
+			want := normalizeWhitespace(test.want.ConvgenWarning)
+			have := normalizeWhitespace(relPathInString(result.Warnings.Error(), wd))
+			assert.Equal(t, want, have)
+		} else if test.want.ConvgenWarning != "" {
+			assert.Fail(t, "Convgen should have warned")
+		}
+
+		// Check for the generated files in split mode
+		if len(test.want.GeneratedFiles) != 0 {
+			var have []string
+			for name := range result.Files {
+				have = append(have, filepath.ToSlash(name))
+			}
+			assert.ElementsMatch(t, test.want.GeneratedFiles, have)
+		}
+
 		// Write generated files
-		for name, content := range generated {
+		for name, content := range result.Files {
 			err := os.WriteFile(filepath.Join(wd, name), content, 0o666)
 			require.NoError(t, err, "Failed to write a generated file")
 		}
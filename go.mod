@@ -4,16 +4,16 @@ go 1.25.0
 
 require (
 	github.com/emirpasic/gods v1.18.1
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/stretchr/testify v1.10.0
 	golang.org/x/sys v0.32.0
 	golang.org/x/text v0.18.0
 	golang.org/x/tools v0.32.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/mod v0.24.0 // indirect
 	golang.org/x/sync v0.13.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
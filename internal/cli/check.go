@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/pmezard/go-difflib/difflib"
+
+	convgeninternal "github.com/sublee/convgen/internal/convgen"
+)
+
+// check compares the generated files with the files on disk without writing
+// them. It writes a unified diff for each outdated file and a line for each
+// orphaned file to w. It returns the number of files out of date.
+func check(w io.Writer, wd string, result convgeninternal.Result) (int, error) {
+	n := 0
+
+	for _, out := range slices.Sorted(maps.Keys(result.Files)) {
+		want := result.Files[out]
+
+		from := out
+		have, err := os.ReadFile(out)
+		if errors.Is(err, fs.ErrNotExist) {
+			from = "/dev/null"
+		} else if err != nil {
+			return n, err
+		}
+
+		if bytes.Equal(have, want) {
+			continue
+		}
+		n++
+
+		if relOut, err := filepath.Rel(wd, out); err == nil {
+			out = relOut
+			if from != "/dev/null" {
+				from = relOut
+			}
+		}
+
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(string(have)),
+			B:        difflib.SplitLines(string(want)),
+			FromFile: from,
+			ToFile:   out,
+			Context:  3,
+		})
+		if err != nil {
+			return n, err
+		}
+		fmt.Fprint(w, diff)
+	}
+
+	for _, orphan := range result.Orphans {
+		n++
+		if relOrphan, err := filepath.Rel(wd, orphan); err == nil {
+			orphan = relOrphan
+		}
+		fmt.Fprintf(w, "orphaned: %s has no convgen files to generate it\n", orphan)
+	}
+
+	return n, nil
+}
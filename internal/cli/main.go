@@ -0,0 +1,311 @@
+// Package cli implements the convgen command. It is shared by the convgen
+// command and custom builds of it with plugins.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	convgeninternal "github.com/sublee/convgen/internal/convgen"
+	"github.com/sublee/convgen/internal/diag"
+)
+
+// flags holds the command-line flags of the convgen command. It is separate
+// from [flag.CommandLine] so that importing this package does not define flags
+// in other programs.
+var flags = flag.NewFlagSet("convgen", flag.ExitOnError)
+
+var (
+	bFlag = flags.String("b", "", "comma-separated build tags")
+	tFlag = flags.Bool("t", false, "include tests")
+	oFlag = flags.String("o", "convgen_gen.go", "output file name")
+	cFlag = flags.String("c", "auto", "colorize (auto|always|never)")
+
+	strictFlag = flags.Bool("strict", false, "treat warnings as errors")
+	jsonFlag   = flags.Bool("json", false, "print diagnostics as JSON to stdout")
+	sarifFlag  = flags.Bool("sarif", false, "print diagnostics as SARIF to stdout")
+	checkFlag  = flags.Bool("check", false, "check whether generated files are up to date without writing them")
+	watchFlag  = flags.Bool("watch", false, "watch files and regenerate affected packages on change")
+	splitFlag  = flags.Bool("split", false, "generate one file per convgen file instead of a single output file")
+	cacheFlag  = flags.String("cache", defaultCacheDir(), "directory to cache generated files (empty to disable)")
+	vFlag      = flags.Bool("v", false, "verbose: report cache hits and misses")
+	jFlag      = flags.Int("j", runtime.GOMAXPROCS(0), "maximum number of packages to build concurrently")
+
+	overlayFlag   = flags.String("overlay", "", "JSON file to replace files on disk as in go build -overlay")
+	explainFlag   = flags.String("explain", "", "print the full match table of the named converter without writing files")
+	manifestFlag  = flags.String("manifest", "", "also write a mapping manifest of converters in each package (json|yaml)")
+	roundTripFlag = flags.Bool("roundtrip", false, "also generate round-trip tests and fuzz targets for converters of types to each other")
+	graphFlag     = flags.String("graph", "", "print the dependency graph of converters without writing files (dot|json)")
+)
+
+// version is the version of the convgen command.
+var version string
+
+// Main runs the convgen command with the command-line arguments. It exits the
+// process on failure.
+func Main(v string) {
+	version = v
+	convgeninternal.Version = v
+	_ = flags.Parse(os.Args[1:])
+
+	wd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	color := false
+	switch *cFlag {
+	case "auto":
+		color = isatty()
+	case "always":
+		color = true
+	case "never":
+		color = false
+	default:
+		fmt.Fprintln(os.Stderr, "invalid -c value:", *cFlag)
+		os.Exit(1)
+	}
+
+	if *jsonFlag && *sarifFlag {
+		fmt.Fprintln(os.Stderr, "cannot use -json and -sarif together")
+		os.Exit(1)
+	}
+	if *watchFlag && *checkFlag {
+		fmt.Fprintln(os.Stderr, "cannot use -watch and -check together")
+		os.Exit(1)
+	}
+	if *explainFlag != "" && (*watchFlag || *checkFlag) {
+		fmt.Fprintln(os.Stderr, "cannot use -explain with -watch or -check")
+		os.Exit(1)
+	}
+
+	if *graphFlag != "" && *graphFlag != "dot" && *graphFlag != "json" {
+		fmt.Fprintln(os.Stderr, "invalid -graph value:", *graphFlag)
+		os.Exit(1)
+	}
+	if *graphFlag != "" && (*watchFlag || *checkFlag || *explainFlag != "" || *jsonFlag || *sarifFlag) {
+		fmt.Fprintln(os.Stderr, "cannot use -graph with -watch, -check, -explain, -json, or -sarif")
+		os.Exit(1)
+	}
+	if *manifestFlag != "" && *manifestFlag != "json" && *manifestFlag != "yaml" {
+		fmt.Fprintln(os.Stderr, "invalid -manifest value:", *manifestFlag)
+		os.Exit(1)
+	}
+
+	var overlay map[string][]byte
+	if *overlayFlag != "" {
+		overlay, err = convgeninternal.ReadOverlay(wd, *overlayFlag)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	opts := convgeninternal.Options{
+		WD:        wd,
+		Env:       os.Environ(),
+		Tags:      *bFlag,
+		Tests:     *tFlag,
+		OutFile:   *oFlag,
+		Split:     *splitFlag,
+		Patterns:  flags.Args(),
+		Overlay:   overlay,
+		Strict:    *strictFlag,
+		Jobs:      *jFlag,
+		CacheDir:  *cacheFlag,
+		Explain:   *explainFlag,
+		Manifest:  *manifestFlag,
+		Graph:     *graphFlag,
+		RoundTrip: *roundTripFlag,
+	}
+
+	if *watchFlag {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		err := convgeninternal.Watch(ctx, opts, watchInterval, func(result convgeninternal.Result, err error) {
+			emit(wd, color, result, err)
+		})
+		if err != nil && !errors.Is(err, context.Canceled) {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	result, err := convgeninternal.Main(context.Background(), opts)
+	if !emit(wd, color, result, err) {
+		os.Exit(1)
+	}
+}
+
+// watchInterval is the polling interval of the watch mode.
+const watchInterval = 500 * time.Millisecond
+
+// emit prints diagnostics of a run and writes the generated files. In check
+// mode, it compares the generated files with the files on disk instead. It
+// reports whether the run succeeded.
+func emit(wd string, color bool, result convgeninternal.Result, err error) bool {
+	machine := *jsonFlag || *sarifFlag
+
+	if *vFlag {
+		for _, id := range result.CacheHits {
+			fmt.Fprintln(os.Stderr, "cache hit:", id)
+		}
+		for _, id := range result.CacheMisses {
+			fmt.Fprintln(os.Stderr, "cache miss:", id)
+		}
+	}
+
+	if machine {
+		// Errors and warnings are written to stdout in a structured format.
+		// Other messages are written to stderr not to break the format.
+		diags := diag.Collect(wd, errors.Join(err, result.Warnings, result.Infos))
+		var writeErr error
+		if *sarifFlag {
+			writeErr = diag.WriteSARIF(os.Stdout, diags, version)
+		} else {
+			writeErr = diag.WriteJSON(os.Stdout, diags)
+		}
+		if writeErr != nil {
+			fmt.Fprintln(os.Stderr, writeErr)
+			return false
+		}
+		if err != nil {
+			return false
+		}
+	}
+
+	if err != nil {
+		message := err.Error()
+		if color {
+			message = colorize(message)
+		}
+		fmt.Fprintln(os.Stderr, message)
+		return false
+	}
+
+	if result.Warnings != nil && !machine {
+		message := result.Warnings.Error()
+		if color {
+			message = colorize(message)
+		}
+		fmt.Fprintln(os.Stderr, message)
+	}
+
+	stdout := os.Stdout
+	if machine {
+		stdout = os.Stderr
+	}
+
+	if *explainFlag != "" {
+		// Explanations replace the generation. Nothing is written.
+		if result.Infos != nil && !machine {
+			fmt.Fprintln(stdout, result.Infos.Error())
+		}
+		return true
+	}
+
+	if *graphFlag != "" {
+		// The graph replaces the generation. Nothing is written.
+		_, _ = os.Stdout.Write(result.Graph)
+		return true
+	}
+
+	if *checkFlag {
+		n, err := check(stdout, wd, result)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return false
+		}
+		if n != 0 {
+			fmt.Fprintf(os.Stderr, "%d generated file(s) out of date; run convgen to update\n", n)
+			return false
+		}
+		return true
+	}
+
+	for _, out := range slices.Sorted(maps.Keys(result.Files)) {
+		if err := os.WriteFile(out, result.Files[out], 0o644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return false
+		}
+
+		if relOut, err := filepath.Rel(wd, out); err == nil {
+			out = relOut
+		}
+		fmt.Fprintln(stdout, "Generated:", out)
+	}
+
+	// Orphaned files would break the build with stale code.
+	for _, orphan := range result.Orphans {
+		if err := os.Remove(orphan); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return false
+		}
+		fmt.Fprintln(stdout, "Removed:", orphan)
+	}
+	return true
+}
+
+// defaultCacheDir returns the default directory for the cache under the user
+// cache directory. It returns an empty string to disable the cache if there is
+// no user cache directory.
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "convgen")
+}
+
+// isatty reports whether the program is running in a terminal. If it is true,
+// we can use ANSI color codes.
+func isatty() bool {
+	_, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	return err == nil
+}
+
+var (
+	reTab  = regexp.MustCompile(`(?m)^\t.+`)
+	reFail = regexp.MustCompile(`^\tFAIL:.+`)
+	reHint = regexp.MustCompile(`^\thint:.+`)
+	reWarn = regexp.MustCompile(`(?m)^\S+: warning: .+`)
+)
+
+// colorize adds ANSI color codes to the message.
+func colorize(message string) string {
+	const (
+		red    = "\033[31m"
+		yellow = "\033[33m"
+		dim    = "\033[2m"
+		reset  = "\033[0m"
+	)
+	m := []byte(message)
+	m = reWarn.ReplaceAllFunc(m, func(b []byte) []byte {
+		return []byte(yellow + string(b) + reset)
+	})
+	m = reTab.ReplaceAllFunc(m, func(b []byte) []byte {
+		if reFail.Match(b) {
+			return []byte(red + string(b) + reset)
+		}
+		if reHint.Match(b) {
+			return []byte(yellow + string(b) + reset)
+		}
+		return []byte(dim + string(b) + reset)
+	})
+	return string(m)
+}
@@ -5,12 +5,46 @@ import (
 	"go/token"
 )
 
+// Severity classifies a [CodeError].
+type Severity int
+
+const (
+	// SeverityError is for problems that prevent code generation.
+	SeverityError Severity = iota
+
+	// SeverityWarning is for suspicious code that does not prevent code
+	// generation, such as an option which takes no effect.
+	SeverityWarning
+
+	// SeverityInfo is for information requested by the user, such as an
+	// explanation of a converter.
+	SeverityInfo
+)
+
+// String returns the lowercase name of the severity, like "warning".
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "info"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
 // CodeError indicates where the error occurred in user's source code.
 type CodeError struct {
-	err  error
-	pos  token.Pos
-	end  token.Pos
-	fset *token.FileSet
+	err      error
+	pos      token.Pos
+	end      token.Pos
+	fset     *token.FileSet
+	severity Severity
+
+	fixes     []Fix
+	converter string
+	rows      []MatchRow
 }
 
 // Unwrap returns the underlying error.
@@ -22,23 +56,77 @@ func (e CodeError) Pos() token.Pos { return e.pos }
 // End returns the end position of the error. It may be invalid.
 func (e CodeError) End() token.Pos { return e.end }
 
+// Severity returns the severity of the error.
+func (e CodeError) Severity() Severity { return e.severity }
+
+// Fixes returns the suggested fixes for the error. It may be empty.
+func (e CodeError) Fixes() []Fix { return e.fixes }
+
+// Converter returns the name of the converter function which the error belongs
+// to. It is empty if the error is not specific to a converter.
+func (e CodeError) Converter() string { return e.converter }
+
+// MatchRows returns the rows of the match table if the error is a match
+// failure. It may be empty.
+func (e CodeError) MatchRows() []MatchRow { return e.rows }
+
+// Position returns the resolved start position. It is invalid if the position
+// is unknown.
+func (e CodeError) Position() token.Position { return e.position(e.pos) }
+
+// EndPosition returns the resolved end position. It is invalid if the end
+// position is unknown.
+func (e CodeError) EndPosition() token.Position { return e.position(e.end) }
+
+func (e CodeError) position(pos token.Pos) token.Position {
+	if e.fset == nil || !pos.IsValid() {
+		return token.Position{}
+	}
+	return e.fset.Position(pos)
+}
+
 // Error implements the error interface. If pos is valid, the position is
-// prepended to the error message.
+// prepended to the error message. Warnings are marked with "warning:" and
+// informational messages with "info:".
 func (e CodeError) Error() string {
 	if e.err == nil {
 		return ""
 	}
 
+	msg := e.err.Error()
+	switch e.severity {
+	case SeverityWarning:
+		msg = "warning: " + msg
+	case SeverityInfo:
+		msg = "info: " + msg
+	}
+
 	if !e.pos.IsValid() {
-		return e.err.Error()
+		return msg
 	}
 
-	return fmt.Sprintf("%s: %s", FormatPosition(e.fset.Position(e.pos)), e.err.Error())
+	return fmt.Sprintf("%s: %s", FormatPosition(e.fset.Position(e.pos)), msg)
 }
 
 // Errorf formats an error message. The error will indicate the position in the
 // source code if the position is valid.
 func (f Formatter) Errorf(poser Poser, format string, args ...any) error {
+	return f.newError(SeverityError, poser, format, args...)
+}
+
+// Warnf formats a warning message like [Formatter.Errorf]. The returned error
+// has [SeverityWarning].
+func (f Formatter) Warnf(poser Poser, format string, args ...any) error {
+	return f.newError(SeverityWarning, poser, format, args...)
+}
+
+// Infof formats an informational message like [Formatter.Errorf]. The returned
+// error has [SeverityInfo].
+func (f Formatter) Infof(poser Poser, format string, args ...any) error {
+	return f.newError(SeverityInfo, poser, format, args...)
+}
+
+func (f Formatter) newError(severity Severity, poser Poser, format string, args ...any) error {
 	// Prevent wrapping error in args
 	for _, arg := range args {
 		if _, ok := arg.(error); ok {
@@ -56,5 +144,63 @@ func (f Formatter) Errorf(poser Poser, format string, args ...any) error {
 
 	args = f.wrapPrintfArgs(args)
 	err := fmt.Errorf(format, args...)
-	return &CodeError{err, pos, end, f.Fset}
+	return &CodeError{err: err, pos: pos, end: end, fset: f.Fset, severity: severity}
+}
+
+// Fix is a suggested fix for a [CodeError]. All edits of a fix should be
+// applied together to resolve the error.
+type Fix struct {
+	Message string
+	Edits   []Edit
+}
+
+// Edit replaces the source code between Pos and End with NewText. If Pos and
+// End are the same, NewText is inserted at Pos.
+type Edit struct {
+	Pos     token.Pos
+	End     token.Pos
+	NewText string
+}
+
+// WithFixes attaches the given fixes to err if it is a [CodeError]. Otherwise,
+// err is returned as is.
+func WithFixes(err error, fixes ...Fix) error {
+	if codeErr, ok := err.(*CodeError); ok {
+		codeErr.fixes = append(codeErr.fixes, fixes...)
+	}
+	return err
+}
+
+// MatchRow is a row of the match table between Xs and Ys. X or Y is "?" if it
+// is missing.
+type MatchRow struct {
+	OK      bool
+	Skipped bool
+	X, Y    string
+	Reason  string
+}
+
+// WithMatchRows attaches the rows of a match table to err if it is a
+// [CodeError]. Otherwise, err is returned as is.
+func WithMatchRows(err error, rows ...MatchRow) error {
+	if codeErr, ok := err.(*CodeError); ok {
+		codeErr.rows = append(codeErr.rows, rows...)
+	}
+	return err
+}
+
+// WithConverter sets the converter name of every [CodeError] in err, including
+// joined ones, unless it is already set.
+func WithConverter(err error, name string) error {
+	switch err := err.(type) {
+	case *CodeError:
+		if err.converter == "" {
+			err.converter = name
+		}
+	case interface{ Unwrap() []error }:
+		for _, err := range err.Unwrap() {
+			WithConverter(err, name)
+		}
+	}
+	return err
 }
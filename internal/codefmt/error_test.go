@@ -38,3 +38,13 @@ func TestErrorfW(t *testing.T) {
 		_ = codefmt.Errorf(pkger{}, poser{1}, "error: %w", assert.AnError)
 	})
 }
+
+func TestWarnf(t *testing.T) {
+	err := codefmt.Warnf(pkger{}, poser{1}, "ineffective %s", "option")
+	assert.Equal(t, "test.go:1:1: warning: ineffective option", err.Error())
+
+	codeErr, ok := err.(*codefmt.CodeError)
+	assert.True(t, ok)
+	assert.Equal(t, codefmt.SeverityWarning, codeErr.Severity())
+	assert.Equal(t, "ineffective option", codeErr.Unwrap().Error())
+}
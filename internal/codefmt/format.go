@@ -18,13 +18,17 @@ type Formatter struct {
 	PkgPath   string
 	Fset      *token.FileSet
 	TypesInfo *types.Info
+
+	// names maps package paths to their names in generated code. If a package
+	// is not in it, its own name is used.
+	names map[string]string
 }
 
 func New(pkg *packages.Package) Formatter {
 	if pkg == nil {
 		return Formatter{}
 	}
-	return Formatter{pkg.PkgPath, pkg.Fset, pkg.TypesInfo}
+	return Formatter{PkgPath: pkg.PkgPath, Fset: pkg.Fset, TypesInfo: pkg.TypesInfo}
 }
 
 func newByPkger(pkger Pkger) Formatter {
@@ -39,6 +43,9 @@ func (f Formatter) qf(pkg *types.Package) string {
 	if pkg.Path() == f.PkgPath {
 		return ""
 	}
+	if name, ok := f.names[pkg.Path()]; ok {
+		return name
+	}
 	return pkg.Name()
 }
 
@@ -51,6 +51,14 @@ func Errorf(pkger Pkger, poser Poser, format string, args ...any) error {
 	return newByPkger(pkger).Errorf(poser, format, args...)
 }
 
+func Warnf(pkger Pkger, poser Poser, format string, args ...any) error {
+	return newByPkger(pkger).Warnf(poser, format, args...)
+}
+
+func Infof(pkger Pkger, poser Poser, format string, args ...any) error {
+	return newByPkger(pkger).Infof(poser, format, args...)
+}
+
 type pkger struct{ pkg *packages.Package }
 
 func (p pkger) Pkg() *packages.Package { return p.pkg }
@@ -5,6 +5,7 @@ import (
 	"go/token"
 	"go/types"
 	"io"
+	"strings"
 
 	"golang.org/x/tools/go/ast/astutil"
 	"golang.org/x/tools/go/packages"
@@ -16,17 +17,28 @@ type Writer struct {
 	pkg     *packages.Package
 	fmt     Formatter
 	imports map[string]Import
+	names   map[string]string
+	used    map[string]bool
 	ns      NS
 }
 
 // NewWriter creates a new [Writer]. It does not initialize the
 // namespace. To specify a namespace, use [SetNamespace].
 func NewWriter(w io.Writer, pkg *packages.Package) *Writer {
+	// Imported packages are referred to by their names in the writer. They
+	// are kept in the writer rather than renaming the packages because
+	// packages are shared by other writers which may run concurrently.
+	names := make(map[string]string)
+	fmt := New(pkg)
+	fmt.names = names
+
 	return &Writer{
 		w:       w,
 		pkg:     pkg,
-		fmt:     New(pkg),
+		fmt:     fmt,
 		imports: make(map[string]Import),
+		names:   names,
+		used:    make(map[string]bool),
 		ns:      nil,
 	}
 }
@@ -66,6 +78,23 @@ func (w *Writer) WithBuf(buf io.Writer) *Writer {
 		pkg:     w.pkg,
 		fmt:     w.fmt,
 		imports: w.imports,
+		names:   w.names,
+		used:    w.used,
+		ns:      w.ns,
+	}
+}
+
+// ForFile copies the writer to write another file with a new write buffer.
+// The copy shares import names with the writer to keep them consistent across
+// files, but [Imports] of the copy returns only the imports used by itself.
+func (w *Writer) ForFile(buf io.Writer) *Writer {
+	return &Writer{
+		w:       buf,
+		pkg:     w.pkg,
+		fmt:     w.fmt,
+		imports: w.imports,
+		names:   w.names,
+		used:    make(map[string]bool),
 		ns:      w.ns,
 	}
 }
@@ -77,6 +106,8 @@ func (w *Writer) WithNS(ns NS) *Writer {
 		pkg:     w.pkg,
 		fmt:     w.fmt,
 		imports: w.imports,
+		names:   w.names,
+		used:    w.used,
 		ns:      ns,
 	}
 }
@@ -92,7 +123,11 @@ type Import struct {
 // Imports returns the collected imports. Imports are collected by [Ref] and
 // [Type].
 func (w *Writer) Imports() map[string]Import {
-	return w.imports
+	imports := make(map[string]Import, len(w.used))
+	for name := range w.used {
+		imports[name] = w.imports[name]
+	}
+	return imports
 }
 
 // importAST records packages used in the given AST node to import later.
@@ -133,19 +168,7 @@ func (w *Writer) importObj(obj types.Object) {
 		return
 	}
 
-	for name := range DisambiguateName(pkg.Name()) {
-		prev, ok := w.imports[name]
-		if ok && prev.Package == pkg {
-			// Already imported with the same name.
-			return
-		}
-		if !ok && w.pkg.Types.Scope().Lookup(name) == nil {
-			// There's no conflict. Import the package with its original name.
-			w.imports[name] = Import{Package: pkg, HasAlias: name != pkg.Name()}
-			pkg.SetName(name)
-			return
-		}
-	}
+	w.Import(pkg.Path(), pkg.Name())
 }
 
 // Import adds an import for the package with the given path and alias. It
@@ -170,17 +193,23 @@ func (w *Writer) Import(path, name string) string {
 	if name == "" {
 		name = pkgName
 	}
-	pkg := types.NewPackage(path, name)
+	if name == "" {
+		// Not imported by the package yet. Guess from the path.
+		name = path[strings.LastIndex(path, "/")+1:]
+	}
+
+	if prev, ok := w.names[path]; ok {
+		// Already imported. A package is imported only once.
+		w.used[prev] = true
+		return prev
+	}
 
 	for name := range DisambiguateName(name) {
-		prev, ok := w.imports[name]
-		if ok && prev.Path() == path {
-			// Already imported with the same name.
-			return name
-		}
-		if !ok && w.pkg.Types.Scope().Lookup(name) == nil {
-			w.imports[name] = Import{Package: pkg, HasAlias: name != pkgName}
-			pkg.SetName(name)
+		if _, ok := w.imports[name]; !ok && w.pkg.Types.Scope().Lookup(name) == nil {
+			// There's no conflict.
+			w.imports[name] = Import{Package: types.NewPackage(path, name), HasAlias: name != pkgName}
+			w.names[path] = name
+			w.used[name] = true
 			return name
 		}
 	}
@@ -73,9 +73,10 @@ func (fac *factory) checkConvertible(x, y Object) error {
 	}
 
 Error:
-	return codefmt.Errorf(fac, fac.inj, `narrowing from %s to %s causes precision loss
+	err := codefmt.Errorf(fac, fac.inj, `narrowing from %s to %s causes precision loss
 	consider convgen.ImportFunc(func(%t) %t) for explicit conversion`,
 		x.DebugName(), y.DebugName(), x, y)
+	return withFix(err)(fac.inj.FixImportFunc(x.Type().Type(), y.Type().Type()))
 }
 
 func kindSizeOf(kind types.BasicKind) int {
@@ -129,6 +129,11 @@ func (fac *factory) build(x, y Object) (assigner, error) {
 		return as, err
 	}
 
+	// Plugins
+	if as, err := fac.tryPlugin(x, y); !errors.Is(err, skip) {
+		return as, err
+	}
+
 	// Primitive types
 	if as, err := fac.tryPointer(x, y); !errors.Is(err, skip) {
 		return as, err
@@ -156,9 +161,23 @@ func (fac *factory) build(x, y Object) (assigner, error) {
 		return as, err
 	}
 
-	return nil, codefmt.Errorf(fac, fac.inj, `cannot convert %s to %s
+	err := codefmt.Errorf(fac, fac.inj, `cannot convert %s to %s
 	consider convgen.ImportFunc(func(%t) %t) for explicit conversion`,
 		x.DebugName(), y.DebugName(), x, y)
+	return nil, withFix(err)(fac.inj.FixImportFunc(x.Type().Type(), y.Type().Type()))
+}
+
+// withFix returns a function that attaches the fix to err if ok is true. It is
+// designed to take the results of the Fix* methods of [parse.Injector]:
+//
+//	return withFix(err)(fac.inj.FixErr())
+func withFix(err error) func(codefmt.Fix, bool) error {
+	return func(fix codefmt.Fix, ok bool) error {
+		if ok {
+			return codefmt.WithFixes(err, fix)
+		}
+		return err
+	}
 }
 
 // skip is returned by tryXXX methods to indicate that the method cannot handle
@@ -20,6 +20,7 @@ type enumAssigner struct {
 	default_ *types.Const
 	pairs    [][2]enumMember
 	errWrap  *errWrapAssigner
+	matcher  *match.Matcher[enumMember]
 }
 
 // requiresErr always returns false.
@@ -62,6 +63,7 @@ func (fac *factory) tryEnum(x, y Object, default_ *types.Const) (*enumAssigner,
 		default_: default_,
 		pairs:    pairs,
 		errWrap:  fac.newErrWrap(),
+		matcher:  m,
 	}, nil
 }
 
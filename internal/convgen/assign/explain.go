@@ -0,0 +1,118 @@
+package assign
+
+import (
+	"fmt"
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/sublee/convgen/internal/codefmt"
+)
+
+// Explain explains how the converter assigns its input to its output. It
+// returns the match table of the converter followed by the match tables of the
+// implicit subconverters it depends on. Each row of the tables shows the keys
+// of the pair after renamers and the way to assign them.
+func Explain(c Conv) string {
+	e := explainer{seen: make(map[*subconv]bool)}
+	switch c := c.(type) {
+	case *conv[assigner]:
+		e.pkg = c.pkg
+		e.explain(c.assigner)
+	case *subconv:
+		e.pkg = c.conv.pkg
+		e.seen[c] = true
+		e.explain(c.conv.assigner)
+	}
+
+	// Subconverters are explained in the order of discovery.
+	for i := 0; i < len(e.queue); i++ {
+		s := e.queue[i]
+		codefmt.Fprintf(codefmt.Pkg(e.pkg), &e.b, "\n\n%s: %t -> %t\n", s.Name(), s.X(), s.Y())
+		e.explain(s.conv.assigner)
+	}
+	return e.b.String()
+}
+
+// explainer collects explanations of a converter and its subconverters.
+type explainer struct {
+	pkg   *packages.Package
+	b     strings.Builder
+	seen  map[*subconv]bool
+	queue []*subconv
+
+	// funcs are the declared functions used by the described assigners.
+	funcs []types.Object
+}
+
+// explain writes the match table of the assigner.
+func (e *explainer) explain(as assigner) {
+	switch as := as.(type) {
+	case *structAssigner:
+		e.b.WriteString(as.matcher.Explain(func(x, y structField) string {
+			return e.describe(findAssigner(as.matches, x, y))
+		}))
+	case *unionAssigner:
+		e.b.WriteString(as.matcher.Explain(func(x, y unionImpl) string {
+			return e.describe(findAssigner(as.matches, x, y))
+		}))
+	case *enumAssigner:
+		e.b.WriteString(as.matcher.Explain(func(x, y enumMember) string {
+			return "constant"
+		}))
+	default:
+		e.b.WriteString(e.describe(as))
+	}
+}
+
+// describe returns a short description of the way the assigner assigns X to Y.
+// Subconverters found in the assigner are queued to be explained.
+func (e *explainer) describe(as assigner) string {
+	switch as := as.(type) {
+	case nil:
+		return ""
+	case *subconv:
+		if !e.seen[as] {
+			e.seen[as] = true
+			e.queue = append(e.queue, as)
+		}
+		return "subconverter " + as.Name()
+	case *funcAssigner:
+		if as.Name() == "" {
+			return "function literal"
+		}
+		if !slices.Contains(e.funcs, as.Object()) {
+			e.funcs = append(e.funcs, as.Object())
+		}
+		return codefmt.Sprintf(codefmt.Pkg(e.pkg), "function %o", as.Func)
+	case *pluginAssigner:
+		return "plugin " + as.name
+	case *basicAssigner:
+		if as.convertible {
+			return codefmt.Sprintf(codefmt.Pkg(e.pkg), "basic cast to %t", as.y)
+		}
+		return "assignment"
+	case *sameAssigner:
+		return "assignment"
+	case *pointerAssigner:
+		return "pointer, " + e.describe(as.assigner)
+	case *indexAssigner:
+		return "loop over elements, " + e.describe(as.assigner)
+	case *keyAssigner:
+		return fmt.Sprintf("loop over keys, %s for keys, %s for elements", e.describe(as.key), e.describe(as.elem))
+	}
+	return fmt.Sprintf("%T", as)
+}
+
+// findAssigner returns the assigner of the match of x and y. It returns nil if
+// x and y are not matched.
+func findAssigner[T Object](matches []matchAssigner[T], x, y T) assigner {
+	for _, m := range matches {
+		if m.X.Pos() == x.Pos() && m.Y.Pos() == y.Pos() {
+			return m.assigner
+		}
+	}
+	return nil
+}
@@ -1,9 +1,12 @@
 package assign
 
 import (
+	"go/ast"
 	"go/token"
+	"strings"
 
 	"github.com/sublee/convgen/internal/codefmt"
+	"github.com/sublee/convgen/internal/convgen/parse"
 	"github.com/sublee/convgen/internal/typeinfo"
 )
 
@@ -11,13 +14,19 @@ import (
 //
 //	y = fn(x)      // for errorless function
 //	y, err = fn(x) // for function with error
+//	if v, ok := fn(x); ok { y = v } // for comma-ok function
 type funcAssigner struct {
 	typeinfo.Func
 	x, y    Object
 	errWrap *errWrapAssigner
+
+	// okErr indicates whether false reported by a comma-ok function is an
+	// error. Otherwise, y is left as zero value or set to fallback.
+	okErr    bool
+	fallback ast.Expr
 }
 
-func (as funcAssigner) requiresErr() bool { return as.Func.HasErr() }
+func (as funcAssigner) requiresErr() bool { return as.Func.HasErr() || as.okErr }
 
 // callFunc creates a [funcAssigner] that calls the given function to convert x
 // to y.
@@ -26,13 +35,28 @@ func (fac *factory) callFunc(x, y Object, fn typeinfo.Func) (*funcAssigner, erro
 		// Function has error, but may not return it.
 		err := codefmt.Errorf(fac, fac.inj, "cannot call %o to convert %s to %s: error return required",
 			fn, x.DebugName(), y.DebugName())
-		return nil, err
+		return nil, withFix(err)(fac.inj.FixErr())
+	}
+
+	var fallback ast.Expr
+	if fn, ok := fn.(parse.OkFunc); ok {
+		fallback = fn.Fallback
+	}
+	okErr := fn.HasOk() && fallback == nil && fac.inj.HasErr()
+	if okErr && !fac.allowsErr {
+		// Function may report false, which must be an error for the converter
+		// returning error.
+		return nil, codefmt.Errorf(fac, fac.inj, "cannot call %o to convert %s to %s: error return required",
+			fn, x.DebugName(), y.DebugName())
 	}
+
 	return &funcAssigner{
-		Func:    fn,
-		x:       x,
-		y:       y,
-		errWrap: fac.newErrWrap(),
+		Func:     fn,
+		x:        x,
+		y:        y,
+		errWrap:  fac.newErrWrap(),
+		okErr:    okErr,
+		fallback: fallback,
 	}, nil
 }
 
@@ -46,16 +70,45 @@ func (fac *factory) tryMatchFunc(x, y Object) (*funcAssigner, error) {
 }
 
 // tryModuleFunc tries to call a function that is registered in the module where
-// the target injector is defined. The functions would be:
+// the target injector is defined. The functions are looked up in order:
 //
-// 1. User-imported functions by convgen.ImportFunc or convgen.ImportFuncErr.
-// 2. The explicit converter generated by the target injector.
-// 3. Automatically generated subconverters.
+//  1. Functions imported by the target injector itself, which take precedence
+//     over the functions of the module.
+//  2. Functions imported by convgen.ImportFunc, convgen.ImportFuncErr, or
+//     convgen.ImportModule, and the explicit converters of the module and its
+//     base modules.
+//  3. Instances of generic functions imported by convgen.ImportFuncGeneric or
+//     convgen.ImportFuncGenericErr.
+//
+// It is tried before plugins. Subconverters are not registered in the module,
+// so they are tried after plugins by [factory.trySubconvFunc].
 func (fac *factory) tryModuleFunc(x, y Object) (*funcAssigner, error) {
-	if fn, ok := fac.inj.Module.Get(x.Type(), y.Type()); ok {
-		return fac.callFunc(x, y, fn)
+	fn, ok := fac.inj.Funcs.Get(x.Type(), y.Type())
+	if !ok {
+		fn, ok = fac.inj.Module.Get(x.Type(), y.Type())
 	}
-	return nil, skip
+	if !ok {
+		insts := fac.inj.Module.Instantiate(x.Type(), y.Type())
+		switch len(insts) {
+		case 0:
+			return nil, skip
+		case 1:
+			fn = insts[0]
+		default:
+			var b strings.Builder
+			for _, inst := range insts {
+				codefmt.Fprintf(fac, &b, "\n\tcandidate %o at %b", inst, inst)
+			}
+			return nil, codefmt.Errorf(fac, fac.inj, "ambiguous instantiation to convert %s to %s%s",
+				x.DebugName(), y.DebugName(), b.String())
+		}
+	}
+
+	as, err := fac.callFunc(x, y, fn)
+	if err == nil {
+		fac.inj.Usage().Use(fn.Pos())
+	}
+	return as, err
 }
 
 // writeAssignCode writes code that assigns x to y by calling the function.
@@ -65,6 +118,16 @@ func (as funcAssigner) writeAssignCode(w *codefmt.Writer, varX, varY, varErr str
 	printFunc := func() {
 		if as.Name() != "" {
 			w.Printf("%o", as.Func)
+			if targs := as.TypeArgs(); len(targs) != 0 {
+				w.Printf("[")
+				for i, targ := range targs {
+					if i != 0 {
+						w.Printf(", ")
+					}
+					w.Printf("%t", targ)
+				}
+				w.Printf("]")
+			}
 		} else if as.FuncLit() != nil {
 			w.Printf("%c", as.Func.FuncLit())
 		}
@@ -76,6 +139,26 @@ func (as funcAssigner) writeAssignCode(w *codefmt.Writer, varX, varY, varErr str
 		as.errWrap.writeWrapCode(w, varErr)
 	}
 
+	if as.HasOk() {
+		varTmp, varOk := w.Name("v"), w.Name("ok")
+		w.Printf("if %s, %s := ", varTmp, varOk)
+		printFunc()
+		w.Printf("(%s); %s {\n", varX, varOk)
+		w.Printf("%s = %s\n", varY, varTmp)
+		switch {
+		case as.fallback != nil:
+			w.Printf("} else {\n")
+			w.Printf("%s = %c\n", varY, as.fallback)
+		case as.okErr && varErr != "":
+			varConvgenErrors := w.Import("github.com/sublee/convgen/pkg/convgenerrors", "convgenerrors")
+			w.Printf("} else {\n")
+			w.Printf("%s = %s.Wrap(\"%s\", %s.ErrNotOk)\n", varErr, varConvgenErrors, as.x.QualName(), varConvgenErrors)
+			as.errWrap.writeWrapCode(w, varErr)
+		}
+		w.Printf("}\n")
+		return
+	}
+
 	if as.HasOut() {
 		if as.requiresErr() {
 			w.Printf("if %s := ", varTmpErr)
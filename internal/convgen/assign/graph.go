@@ -0,0 +1,125 @@
+package assign
+
+import (
+	"go/token"
+
+	"github.com/sublee/convgen/internal/convgen/parse"
+	"github.com/sublee/convgen/internal/typeinfo"
+)
+
+// Call is a call from a converter to another function in the generated code.
+// It is an edge of the converter dependency graph.
+type Call struct {
+	// X and Y are the qualified names of the pair converted by the call. They
+	// are empty for error wrappers which are not called for a specific pair.
+	X, Y string
+
+	Callee Callee
+}
+
+// Callee is a function called by a converter.
+type Callee struct {
+	// Kind is one of "converter", "subconverter", "function", "plugin", or
+	// "errwrap".
+	Kind string
+
+	// Name is the name of the converter, subconverter, or plugin. It is empty
+	// for functions and error wrappers. Use Func to name them.
+	Name string
+
+	// Func is the called function. It is nil for plugins.
+	Func typeinfo.Func
+
+	// Err reports whether the callee may return an error.
+	Err bool
+}
+
+// Calls returns the calls from the converter in the order of the pairs. The
+// same callee may be called multiple times for different pairs.
+func Calls(c Conv) []Call {
+	var g callGraph
+	switch as := assignerOf(c).(type) {
+	case *structAssigner:
+		for _, m := range as.matches {
+			g.walk(m.assigner, m.X.QualName(), m.Y.QualName())
+		}
+		if as.requiresErr() {
+			g.errWrap(as.errWrap)
+		}
+	case *unionAssigner:
+		for _, m := range as.matches {
+			g.walk(m.assigner, m.X.QualName(), m.Y.QualName())
+		}
+		if as.requiresErr() {
+			g.errWrap(as.errWrap)
+		}
+	case *enumAssigner:
+		g.errWrap(as.errWrap)
+	}
+	return g.calls
+}
+
+// callGraph collects calls from a converter.
+type callGraph struct {
+	calls []Call
+}
+
+// walk collects calls made by the assigner to convert the pair of x and y.
+func (g *callGraph) walk(as assigner, x, y string) {
+	switch as := as.(type) {
+	case *subconv:
+		g.add(x, y, Callee{Kind: "subconverter", Name: as.Name(), Func: as.Func, Err: as.HasErr()})
+		if as.HasErr() {
+			g.errWrap(as.errWrap)
+		}
+	case *funcAssigner:
+		if inj, ok := as.Func.(parse.Injector); ok {
+			g.add(x, y, Callee{Kind: "converter", Name: inj.Name(), Func: as.Func, Err: as.HasErr()})
+		} else {
+			g.add(x, y, Callee{Kind: "function", Func: as.Func, Err: as.HasErr()})
+		}
+		if as.HasErr() {
+			g.errWrap(as.errWrap)
+		}
+	case *pluginAssigner:
+		g.add(x, y, Callee{Kind: "plugin", Name: as.name, Err: as.requiresErr()})
+		if as.requiresErr() {
+			g.errWrap(as.errWrap)
+		}
+	case *pointerAssigner:
+		g.walk(as.assigner, x, y)
+	case *indexAssigner:
+		g.walk(as.assigner, x, y)
+	case *keyAssigner:
+		g.walk(as.key, x, y)
+		g.walk(as.elem, x, y)
+	}
+}
+
+// errWrap collects calls to the error wrappers in the chain.
+func (g *callGraph) errWrap(as *errWrapAssigner) {
+	for ; as != nil; as = as.next {
+		if as.Func != nil {
+			g.add("", "", Callee{Kind: "errwrap", Func: as.Func, Err: true})
+		}
+	}
+}
+
+// add adds a call unless the same call is already added.
+func (g *callGraph) add(x, y string, callee Callee) {
+	call := Call{X: x, Y: y, Callee: callee}
+	for _, c := range g.calls {
+		if c.X == call.X && c.Y == call.Y && c.Callee.Kind == callee.Kind && c.Callee.Name == callee.Name && funcPos(c.Callee.Func) == funcPos(callee.Func) {
+			return
+		}
+	}
+	g.calls = append(g.calls, call)
+}
+
+// funcPos returns the position of the function, or token.NoPos if fn is nil.
+func funcPos(fn typeinfo.Func) token.Pos {
+	if fn == nil {
+		return token.NoPos
+	}
+	return fn.Pos()
+}
@@ -0,0 +1,113 @@
+package assign
+
+import (
+	"go/token"
+	"go/types"
+
+	"github.com/sublee/convgen/internal/convgen/match"
+)
+
+// Mapping describes how a converter assigns its input to its output. It is the
+// entry of a mapping manifest.
+type Mapping struct {
+	Name     string
+	X, Y     types.Type
+	Implicit bool
+
+	// Kind is "struct", "union", or "enum". It is empty if the converter
+	// assigns its input to its output without matching, then Via describes the
+	// way to assign.
+	Kind string
+	Via  string
+
+	// Pairs are the rows of the match table of the converter.
+	Pairs []Pair
+
+	// Funcs are the declared functions used by the converter directly, such as
+	// functions imported by convgen.ImportFunc.
+	Funcs []types.Object
+}
+
+// Pair is a pair of fields, union implementations, or enum members in a
+// [Mapping]. X or Y is empty if it is missing.
+type Pair struct {
+	X, Y    string
+	Skipped bool
+
+	// Reason explains why the pair is matched or skipped, like "forced" or
+	// "skipped missing". At is the position where the reason comes from, or
+	// token.NoPos.
+	Reason string
+	At     token.Pos
+
+	// Via describes the way to assign X to Y, like "subconverter convUser".
+	Via string
+}
+
+// Describe describes how the converter assigns its input to its output.
+// Subconverters used by the converter are referred by their names. They should
+// be described separately.
+func Describe(c Conv) Mapping {
+	var (
+		m  Mapping
+		as assigner
+		e  = explainer{seen: make(map[*subconv]bool)}
+	)
+	switch c := c.(type) {
+	case *conv[assigner]:
+		e.pkg, as = c.pkg, c.assigner
+		m = Mapping{Name: c.Name(), X: c.X().Type(), Y: c.Y().Type()}
+	case *subconv:
+		e.pkg, as = c.conv.pkg, c.conv.assigner
+		m = Mapping{Name: c.Name(), X: c.X().Type(), Y: c.Y().Type(), Implicit: true}
+	}
+
+	switch as := as.(type) {
+	case *structAssigner:
+		m.Kind = "struct"
+		m.Pairs = describePairs(&e, as.matcher, as.matches)
+	case *unionAssigner:
+		m.Kind = "union"
+		m.Pairs = describePairs(&e, as.matcher, as.matches)
+	case *enumAssigner:
+		m.Kind = "enum"
+		for _, row := range as.matcher.Table() {
+			p := newPair(row)
+			if row.HasX && row.HasY {
+				p.Via = "constant"
+			}
+			m.Pairs = append(m.Pairs, p)
+		}
+	case nil:
+	default:
+		m.Via = e.describe(as)
+	}
+	m.Funcs = e.funcs
+	return m
+}
+
+// describePairs describes the rows of the match table with the matched
+// assigners.
+func describePairs[T Object](e *explainer, m *match.Matcher[T], matches []matchAssigner[T]) []Pair {
+	var pairs []Pair
+	for _, row := range m.Table() {
+		p := newPair(row)
+		if row.HasX && row.HasY {
+			p.Via = e.describe(findAssigner(matches, row.X, row.Y))
+		}
+		pairs = append(pairs, p)
+	}
+	return pairs
+}
+
+// newPair creates a [Pair] from a row of the match table.
+func newPair[T Object](row match.Row[T]) Pair {
+	p := Pair{Skipped: row.Skipped, Reason: row.Reason, At: row.At}
+	if row.HasX {
+		p.X = row.X.QualName()
+	}
+	if row.HasY {
+		p.Y = row.Y.QualName()
+	}
+	return p
+}
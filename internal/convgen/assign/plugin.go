@@ -0,0 +1,123 @@
+package assign
+
+import (
+	"errors"
+	"go/types"
+
+	"github.com/sublee/convgen/internal/codefmt"
+	"github.com/sublee/convgen/internal/typeinfo"
+	"github.com/sublee/convgen/pkg/convgenplugin"
+)
+
+// pluginAssigner assigns x to y with an assignment built by a plugin registered
+// by [convgenplugin.Register].
+type pluginAssigner struct {
+	name string
+	convgenplugin.Assignment
+
+	x       Object
+	errWrap *errWrapAssigner
+}
+
+func (as pluginAssigner) requiresErr() bool { return as.RequiresErr() }
+
+// tryPlugin tries the registered plugins in order to convert x to y.
+func (fac *factory) tryPlugin(x, y Object) (*pluginAssigner, error) {
+	for name, plugin := range convgenplugin.Assigners() {
+		b := &pluginBuilder{fac: fac, x: x, y: y}
+		a, err := plugin.Assign(b, x.Type().Type(), y.Type().Type())
+		if errors.Is(err, convgenplugin.ErrSkip) {
+			continue
+		}
+		if err != nil {
+			if b.err != nil && errors.Is(err, b.err) {
+				// Errors from nested conversions are already reported with
+				// their positions.
+				return nil, err
+			}
+			return nil, codefmt.Errorf(fac, fac.inj, "cannot convert %s to %s by plugin %s: %s",
+				x.DebugName(), y.DebugName(), name, err.Error())
+		}
+
+		if a.RequiresErr() && !fac.allowsErr {
+			err := codefmt.Errorf(fac, fac.inj, "cannot use plugin %s to convert %s to %s: error return required",
+				name, x.DebugName(), y.DebugName())
+			return nil, withFix(err)(fac.inj.FixErr())
+		}
+		return &pluginAssigner{
+			name:       name,
+			Assignment: a,
+			x:          x,
+			errWrap:    fac.newErrWrap(),
+		}, nil
+	}
+	return nil, skip
+}
+
+// writeAssignCode writes code that assigns x to y by the plugin. An error from
+// the plugin is wrapped with the name of x like errors from functions.
+func (as pluginAssigner) writeAssignCode(w *codefmt.Writer, varX, varY, varErr string) {
+	if varErr == "" || !as.RequiresErr() {
+		as.WriteAssign(pluginWriter{w}, varX, varY, varErr)
+		return
+	}
+
+	varTmpErr := w.Name("err")
+	w.Printf("var %s error\n", varTmpErr)
+	as.WriteAssign(pluginWriter{w}, varX, varY, varTmpErr)
+	w.Printf("if %s != nil {\n", varTmpErr)
+	varConvgenErrors := w.Import("github.com/sublee/convgen/pkg/convgenerrors", "convgenerrors")
+	w.Printf("%s = %s.Wrap(\"%s\", %s)\n", varErr, varConvgenErrors, as.x.QualName(), varTmpErr)
+	as.errWrap.writeWrapCode(w, varErr)
+	w.Printf("}\n")
+}
+
+// pluginBuilder implements [convgenplugin.Builder] for a plugin converting x to
+// y.
+type pluginBuilder struct {
+	fac  *factory
+	x, y Object
+
+	// err is the last error of nested conversions.
+	err error
+}
+
+func (b *pluginBuilder) AllowsErr() bool { return b.fac.allowsErr }
+
+// Build builds an assigner for the nested conversion. The objects keep the
+// names of the outer objects to report errors in context.
+func (b *pluginBuilder) Build(x, y types.Type) (convgenplugin.Assignment, error) {
+	as, err := b.fac.build(nestedObject(b.x, x), nestedObject(b.y, y))
+	if err != nil {
+		b.err = err
+		return nil, err
+	}
+	return builtAssignment{as}, nil
+}
+
+// nestedObject returns an object of type t which is nested in o. It has no
+// qualified name because errors of the nested conversion are wrapped again
+// with the name of o by [pluginAssigner].
+func nestedObject(o Object, t types.Type) Object {
+	return anonObject{typeinfo.TypeOf(t), "", o.CrumbName(), o.DebugName(), o.Exported(), o.Pos()}
+}
+
+// builtAssignment exposes an assigner built by Convgen to plugins.
+type builtAssignment struct{ assigner }
+
+func (a builtAssignment) RequiresErr() bool { return a.requiresErr() }
+
+func (a builtAssignment) WriteAssign(w convgenplugin.Writer, varX, varY, varErr string) {
+	pw, ok := w.(pluginWriter)
+	if !ok {
+		panic("convgen: WriteAssign called with a foreign writer")
+	}
+	a.writeAssignCode(pw.w, varX, varY, varErr)
+}
+
+// pluginWriter restricts [codefmt.Writer] to [convgenplugin.Writer].
+type pluginWriter struct{ w *codefmt.Writer }
+
+func (pw pluginWriter) Printf(format string, args ...any) { _, _ = pw.w.Printf(format, args...) }
+func (pw pluginWriter) Import(path, name string) string   { return pw.w.Import(path, name) }
+func (pw pluginWriter) Name(name string) string           { return pw.w.Name(name) }
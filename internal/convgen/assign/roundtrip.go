@@ -0,0 +1,65 @@
+package assign
+
+import "go/types"
+
+// Field is a struct field referred by its owner struct type and name.
+type Field struct {
+	Owner types.Type
+	Name  string
+}
+
+// UnassignedFields returns the fields of X which are not assigned to Y and the
+// fields of Y which are not assigned from X by the struct converter. They are
+// the fields skipped or missing in the match table. Getters and setters are
+// excluded. It returns nil for converters other than struct converters.
+func UnassignedFields(c Conv) (xs, ys []Field) {
+	as, ok := assignerOf(c).(*structAssigner)
+	if !ok {
+		return nil, nil
+	}
+
+	field := func(o structField) (Field, bool) {
+		if o.field == nil {
+			return Field{}, false
+		}
+		return Field{Owner: o.owner.Type().Deref().Type(), Name: o.field.Name()}, true
+	}
+	for _, row := range as.matcher.Table() {
+		if row.HasX && (!row.HasY || row.Skipped) {
+			if f, ok := field(row.X); ok {
+				xs = append(xs, f)
+			}
+		}
+		if row.HasY && (!row.HasX || row.Skipped) {
+			if f, ok := field(row.Y); ok {
+				ys = append(ys, f)
+			}
+		}
+	}
+	return xs, ys
+}
+
+// EnumMembers returns the members of X and Y matched by the enum converter.
+// It returns nil for converters other than enum converters.
+func EnumMembers(c Conv) (xs, ys []*types.Const) {
+	as, ok := assignerOf(c).(*enumAssigner)
+	if !ok {
+		return nil, nil
+	}
+	for _, pair := range as.pairs {
+		xs = append(xs, pair[0].con)
+		ys = append(ys, pair[1].con)
+	}
+	return xs, ys
+}
+
+// assignerOf returns the outermost assigner of the converter.
+func assignerOf(c Conv) assigner {
+	switch c := c.(type) {
+	case *conv[assigner]:
+		return c.assigner
+	case *subconv:
+		return c.conv.assigner
+	}
+	return nil
+}
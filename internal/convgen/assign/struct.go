@@ -24,6 +24,7 @@ type structAssigner struct {
 	x, y    Object // must be struct types
 	matches []matchAssigner[structField]
 	errWrap *errWrapAssigner
+	matcher *match.Matcher[structField]
 }
 
 // requiresErr returns true if any of the matches has an error.
@@ -51,10 +52,11 @@ func (fac *factory) tryStruct(x, y Object) (*structAssigner, error) {
 
 	m := match.NewMatcher[structField](fac.inj, fac.cfg, x, y)
 	errs := discover(fac, m, structDiscovery{
-		cfg: fac.cfg,
-		pkg: fac.Pkg(),
-		x:   x,
-		y:   y,
+		cfg:   fac.cfg,
+		pkg:   fac.Pkg(),
+		usage: fac.inj.Usage(),
+		x:     x,
+		y:     y,
 	})
 	matches, err := m.Match()
 	errs = errors.Join(errs, err)
@@ -62,11 +64,12 @@ func (fac *factory) tryStruct(x, y Object) (*structAssigner, error) {
 	// Check if getters and setters return an error.
 	if !fac.allowsErr {
 		needErr := func(fn typeinfo.Func) error {
-			return codefmt.Errorf(fac, fac.inj, `cannot return error of %o
+			err := codefmt.Errorf(fac, fac.inj, `cannot return error of %o
 	%b: %o (%t)
 	try convgen.StructErr`,
 				fn, fn,
 				fn, fn)
+			return withFix(err)(fac.inj.FixErr())
 		}
 
 		for _, pair := range matches {
@@ -91,6 +94,7 @@ func (fac *factory) tryStruct(x, y Object) (*structAssigner, error) {
 		y:       y,
 		matches: matchAssigners,
 		errWrap: fac.newErrWrap(),
+		matcher: m,
 	}, nil
 }
 
@@ -127,6 +131,29 @@ func (o structField) CrumbName() string {
 	return codefmt.Sprintf(codefmt.Pkg(o.pkg), "%s.%s", o.owner.CrumbName(), o.name)
 }
 
+// PathExpr returns an expression of the field or method for convgen.Match. For
+// example, "Session{}.SignedUser.Name". Setters are referred via a nil pointer
+// like "(*User)(nil).SetName" because they usually have pointer receivers.
+func (o structField) PathExpr() string {
+	names := []string{o.name}
+	owner := o.owner
+	for {
+		f, ok := owner.(structField)
+		if !ok {
+			break
+		}
+		names = append(names, f.name)
+		owner = f.owner
+	}
+	slices.Reverse(names)
+
+	root := codefmt.FormatType(codefmt.Pkg(o.pkg), owner.Type().Deref().Type())
+	if o.setter != nil {
+		return fmt.Sprintf("(*%s)(nil).%s", root, strings.Join(names, "."))
+	}
+	return fmt.Sprintf("%s{}.%s", root, strings.Join(names, "."))
+}
+
 // DebugName returns the crumb name with its type for debugging. For example,
 // "Session.SignedUser.Name (string)".
 func (o structField) DebugName() string {
@@ -164,9 +191,10 @@ func (o structField) Pos() token.Pos {
 
 // structDiscovery discovers fields and getter/setter methods of struct types.
 type structDiscovery struct {
-	pkg  *packages.Package
-	cfg  parse.Config
-	x, y Object
+	pkg   *packages.Package
+	cfg   parse.Config
+	usage *parse.Usage
+	x, y  Object
 }
 
 // DiscoverX discovers fields and getter methods of struct X and nested fields
@@ -254,6 +282,7 @@ func (d structDiscovery) discoverGetters(owner Object, add addFunc[structField])
 		}
 		key := strings.TrimSuffix(strings.TrimPrefix(m.Name(), d.cfg.DiscoverGettersPrefix), d.cfg.DiscoverGettersSuffix)
 		add(field, key)
+		d.usage.Use(d.cfg.DiscoverGettersAt)
 	}
 }
 
@@ -288,6 +317,7 @@ func (d structDiscovery) discoverSetters(owner Object, add addFunc[structField])
 		}
 		key := strings.TrimSuffix(strings.TrimPrefix(m.Name(), d.cfg.DiscoverSettersPrefix), d.cfg.DiscoverSettersSuffix)
 		add(field, key)
+		d.usage.Use(d.cfg.DiscoverSettersAt)
 	}
 }
 
@@ -110,12 +110,18 @@ func (fac *factory) joinForSubconv(forked *factory) {
 	}
 }
 
-// newSubconvName generates a new unique name for a subconverter function.
+// newSubconvName generates a new unique name for a subconverter function. The
+// subconverters of an injector which imports functions by itself are named
+// after the injector because they are not shared with other injectors.
 func (fac *factory) newSubconvName(x, y typeinfo.Type) string {
-	return fac.ns.Name(formatSubconvName(fac.inj.Pkg(), x, y, fac.inj.Module))
+	scope := ""
+	if fac.inj.Funcs != nil {
+		scope = fac.inj.Root().Name()
+	}
+	return fac.ns.Name(formatSubconvName(fac.inj.Pkg(), x, y, fac.inj.Module, scope))
 }
 
-func formatSubconvName(pkg *packages.Package, x, y typeinfo.Type, mod *parse.Module) string {
+func formatSubconvName(pkg *packages.Package, x, y typeinfo.Type, mod *parse.Module, scope string) string {
 	var b strings.Builder
 	b.WriteString("convgen_")
 
@@ -124,6 +130,11 @@ func formatSubconvName(pkg *packages.Package, x, y typeinfo.Type, mod *parse.Mod
 		b.WriteString("_")
 	}
 
+	if scope != "" {
+		b.WriteString(scope)
+		b.WriteString("_")
+	}
+
 	if x.Pkg() != nil && x.Pkg() != pkg.Types {
 		b.WriteString(x.Pkg().Name())
 		b.WriteString("_")
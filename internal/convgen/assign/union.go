@@ -19,6 +19,7 @@ type unionAssigner struct {
 	x, y    Object // must be interfaces
 	matches []matchAssigner[unionImpl]
 	errWrap *errWrapAssigner
+	matcher *match.Matcher[unionImpl]
 }
 
 // requiresErr returns true if any of the matches has an error.
@@ -74,6 +75,7 @@ func (fac *factory) tryUnion(x, y Object) (*unionAssigner, error) {
 		y:       y,
 		matches: matchAssigners,
 		errWrap: fac.newErrWrap(),
+		matcher: m,
 	}, nil
 }
 
@@ -93,6 +95,23 @@ func (o unionImpl) Exported() bool         { return o.impl.Deref().Named.Obj().E
 func (o unionImpl) Pkg() *packages.Package { return o.pkg }
 func (o unionImpl) Pos() token.Pos         { return o.pos }
 
+// PathExpr returns an expression of the implementation for convgen.Match, like
+// "ClickEvent{}" or "&api.ClickEvt{}".
+func (o unionImpl) PathExpr() string {
+	t := o.impl.Deref()
+	name := codefmt.FormatType(o, t.Type())
+	switch {
+	case o.impl.IsPointer() && t.IsStruct():
+		return "&" + name + "{}"
+	case o.impl.IsPointer():
+		return "new(" + name + ")"
+	case t.IsStruct():
+		return name + "{}"
+	default:
+		return "*new(" + name + ")"
+	}
+}
+
 type unionDiscovery struct {
 	cfg  parse.Config
 	pkg  *packages.Package
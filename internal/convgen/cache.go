@@ -0,0 +1,223 @@
+package convgeninternal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/sublee/convgen/internal/convgen/parse"
+)
+
+// cache stores generated files of packages on disk. An entry is keyed by a hash
+// of everything which may affect the generation of a package: the Go files of
+// the package, the export data of its imports, the Go files of its imports with
+// Convgen directives, the options, the build environment, and Convgen itself.
+type cache struct {
+	dir string
+
+	// keys maps package IDs to their cache keys. Files generated for a package
+	// are stored only if it has a key.
+	keys map[string]string
+}
+
+// cacheEntry is the content of a cache file.
+type cacheEntry struct {
+	// Files maps generated file names to their contents.
+	Files map[string][]byte `json:"files"`
+}
+
+func newCache(dir string) *cache {
+	return &cache{dir: dir, keys: make(map[string]string)}
+}
+
+// key computes the cache key of the package. The package must be loaded by
+// [loadExports]. It returns false if some inputs are not available, for
+// example, if an import failed to compile.
+func (c *cache) key(pkg *packages.Package, opts Options) (string, bool) {
+	exe, err := exeHash()
+	if err != nil {
+		return "", false
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "convgen %s %s\n", Version, exe)
+	fmt.Fprintf(h, "pkg %s\n", pkg.ID)
+	fmt.Fprintf(h, "opts tags=%q tests=%t out=%q split=%t manifest=%q roundtrip=%t\n", opts.Tags, opts.Tests, opts.OutFile, opts.Split, opts.Manifest, opts.RoundTrip)
+
+	// Some environment variables affect the types without changing the files,
+	// like sizes by GOARCH.
+	for _, key := range cacheEnvKeys {
+		fmt.Fprintf(h, "env %s=%q\n", key, lookupEnv(opts.Env, key))
+	}
+
+	for _, path := range slices.Sorted(slices.Values(pkg.GoFiles)) {
+		if err := hashFile(h, "file "+filepath.Base(path), path); err != nil {
+			return "", false
+		}
+	}
+
+	// The export data of an import summarizes the types in its API including
+	// the types from indirect imports.
+	for _, path := range slices.Sorted(maps.Keys(pkg.Imports)) {
+		imp := pkg.Imports[path]
+		if imp.ExportFile == "" {
+			if imp.ID == "unsafe" {
+				continue
+			}
+			return "", false
+		}
+		if err := hashFile(h, "import "+imp.ID, imp.ExportFile); err != nil {
+			return "", false
+		}
+
+		// Converters imported by convgen.ImportModule are found in the source
+		// of the import, not in its export data.
+		if !importsConvgen(imp) {
+			continue
+		}
+		for _, path := range slices.Sorted(slices.Values(imp.GoFiles)) {
+			if err := hashFile(h, "import file "+imp.ID+" "+filepath.Base(path), path); err != nil {
+				return "", false
+			}
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// cacheEnvKeys are the environment variables which may affect the generation.
+var cacheEnvKeys = []string{"GOOS", "GOARCH", "GOFLAGS", "CGO_ENABLED", "GOEXPERIMENT"}
+
+// lookupEnv returns the value of the environment variable in env. The last one
+// wins like [os/exec.Cmd.Env]. If env is nil, it looks up the environment of
+// the current process as [packages.Config.Env] does.
+func lookupEnv(env []string, key string) string {
+	if env == nil {
+		return os.Getenv(key)
+	}
+	for _, kv := range slices.Backward(env) {
+		if k, v, ok := strings.Cut(kv, "="); ok && k == key {
+			return v
+		}
+	}
+	return ""
+}
+
+// get returns the generated files stored with the key.
+func (c *cache) get(key string) (map[string][]byte, bool) {
+	data, err := os.ReadFile(filepath.Join(c.dir, key))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	return entry.Files, true
+}
+
+// put stores the generated files of the package. It does nothing if the
+// package has no cache key. Failures are ignored because the cache is only an
+// optimization.
+func (c *cache) put(pkg *packages.Package, files map[string][]byte) {
+	key, ok := c.keys[pkg.ID]
+	if !ok {
+		return
+	}
+
+	data, err := json.Marshal(cacheEntry{Files: files})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return
+	}
+
+	// Write to a temporary file and then rename it to avoid partial entries
+	// read by concurrent runs.
+	tmp, err := os.CreateTemp(c.dir, key+".tmp*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(c.dir, key))
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+}
+
+// importsConvgen reports whether the package imports Convgen directly.
+func importsConvgen(pkg *packages.Package) bool {
+	for path := range pkg.Imports {
+		if parse.IsConvgenImport(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// hashFile writes the label and the content of the file to h.
+func hashFile(h io.Writer, label, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fmt.Fprintf(h, "%s\n", label)
+	_, err = io.Copy(h, f)
+	fmt.Fprintf(h, "\n")
+	return err
+}
+
+// exeHash returns the hash of the running executable. It distinguishes
+// development builds of Convgen which share the same version.
+var exeHash = sync.OnceValues(func() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	if err := hashFile(h, "exe", exe); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+})
+
+// loadExports loads packages without syntax and types but with the export data
+// of their dependencies. It is much cheaper than [load] and enough to compute
+// cache keys.
+func loadExports(ctx context.Context, opts Options) ([]*packages.Package, error) {
+	cfg := &packages.Config{
+		Mode:       packages.NeedDeps | packages.NeedExportFile | packages.NeedFiles | packages.NeedImports | packages.NeedName,
+		Context:    ctx,
+		Dir:        opts.WD,
+		Env:        opts.Env,
+		BuildFlags: buildFlags(opts.Tags),
+		Tests:      opts.Tests,
+	}
+
+	pkgs, err := packages.Load(cfg, opts.Patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages: %w", err)
+	}
+	return pkgs, nil
+}
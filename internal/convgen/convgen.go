@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"go/ast"
+	"go/build"
 	"go/format"
 	"go/printer"
 	"go/token"
@@ -12,6 +13,7 @@ import (
 	"maps"
 	"path/filepath"
 	"slices"
+	"strings"
 
 	"golang.org/x/tools/go/ast/astutil"
 	"golang.org/x/tools/go/packages"
@@ -34,13 +36,25 @@ type Convgen struct {
 	injs     map[token.Pos]parse.Injector
 	convs    map[token.Pos]assign.Conv
 	subconvs map[*parse.Module][]assign.Conv
+	warns    error
+
+	// creators maps implicit subconverters to the names of the explicit
+	// converters which created them.
+	creators map[assign.Conv]string
+
+	// shared holds the subconverters reusable by any converter in the module.
+	// Subconverters of a converter which imports functions by itself are
+	// excluded.
+	shared map[*parse.Module][]assign.Conv
 }
 
 // New creates a new [Convgen] for the given package. If the package does not
 // satisfy the requirements, an error is returned. The package must have its
-// Syntax, Types and TypesInfo. And it must not have any errors.
-func New(pkg *packages.Package) (*Convgen, error) {
-	parser, err := parse.New(pkg)
+// Syntax, Types and TypesInfo. And it must not have any errors. The build
+// context selects the files of imported packages which are loaded without
+// their files. See [BuildContext].
+func New(pkg *packages.Package, ctxt build.Context) (*Convgen, error) {
+	parser, err := parse.New(pkg, ctxt)
 	if err != nil {
 		return nil, err
 	}
@@ -52,6 +66,8 @@ func New(pkg *packages.Package) (*Convgen, error) {
 		buf:      &buf,
 		w:        codefmt.NewWriter(&buf, pkg),
 		subconvs: make(map[*parse.Module][]assign.Conv),
+		creators: make(map[assign.Conv]string),
+		shared:   make(map[*parse.Module][]assign.Conv),
 	}, nil
 }
 
@@ -98,124 +114,299 @@ func (cg *Convgen) Build() error {
 	// Build converters from the definitions.
 	cg.convs = make(map[token.Pos]assign.Conv)
 	for _, inj := range injs {
-		conv, subconvs, err := assign.Build(inj, cg.ns, cg.subconvs[inj.Module])
+		// A converter which imports functions by itself neither reuses nor
+		// shares subconverters, so that the functions apply to all of its
+		// subconverters but nothing else.
+		var shared []assign.Conv
+		if inj.Funcs == nil {
+			shared = cg.shared[inj.Module]
+		}
+
+		conv, subconvs, err := assign.Build(inj, cg.ns, shared)
 		if err != nil {
-			errs = errors.Join(errs, err)
+			errs = errors.Join(errs, codefmt.WithConverter(err, inj.Name()))
 			continue
 		}
 
 		cg.convs[inj.Pos()] = conv
 		cg.subconvs[inj.Module] = append(cg.subconvs[inj.Module], subconvs...)
+		if inj.Funcs == nil {
+			cg.shared[inj.Module] = append(cg.shared[inj.Module], subconvs...)
+		}
+		for _, subconv := range subconvs {
+			cg.creators[subconv] = inj.Name()
+		}
+	}
+	if errs != nil {
+		return errs
 	}
 
-	return errs
+	cg.warns = cg.warnIneffective()
+	return nil
+}
+
+// Warnings returns warnings found by [Build], such as options which take no
+// effect. Warnings do not prevent code generation.
+func (cg *Convgen) Warnings() error {
+	return cg.warns
 }
 
 // Generate generates converter code for the package. It must be called after
-// [Build] succeeds.
+// [Build] succeeds. It returns nil if the package has no Convgen files.
 func (cg *Convgen) Generate() []byte {
-	cg.writeConvCode()
-	cg.mergeCode()
-	return cg.frameCode()
+	files := cg.p.ConvgenGoFiles()
+	if len(files) == 0 {
+		return nil
+	}
+
+	cg.writeExplicitConvs(cg.w, cg.sortedConvs())
+	cg.writeImplicitConvs(cg.w, cg.sortedMods())
+	for _, file := range files {
+		cg.mergeCode(cg.w, cg.buf, file)
+	}
+	return cg.frameCode(cg.w, cg.buf)
 }
 
-// writeConvCode writes function declaration code for explicit and implicit
-// converters.
-func (cg *Convgen) writeConvCode() {
-	if len(cg.convs) != 0 {
-		cg.w.Printf("// convgen: explicit converters\n\n")
+// GenerateFiles generates converter code for the package like [Generate], but
+// splits it into one file per Convgen file. A Convgen file "x.go" generates
+// "x_gen.go" with its explicit converters, and "x_test.go" generates
+// "x_gen_test.go". Implicit converters are shared by converters in any file,
+// so they are generated in pkgFile, or in its "_test.go" variant if their
+// module is declared in a test file.
+//
+// If roundTrip is true, the "_test.go" variant of pkgFile also holds the tests
+// generated by [Convgen.GenerateRoundTripTests].
+//
+// It returns generated code by file names. It must be called after [Build]
+// succeeds. It returns nil if the package has no Convgen files. It fails if a
+// Convgen file generates pkgFile or its "_test.go" variant, such as
+// "convgen.go" with "convgen_gen.go".
+func (cg *Convgen) GenerateFiles(pkgFile string, roundTrip bool) (map[string][]byte, error) {
+	files := cg.p.ConvgenGoFiles()
+	if len(files) == 0 {
+		return nil, nil
+	}
 
-		convs := slices.Collect(maps.Values(cg.convs))
-		slices.SortFunc(convs, func(a, b assign.Conv) int {
-			if a.Pos() < b.Pos() {
-				return -1
-			}
-			if a.Pos() > b.Pos() {
-				return 1
+	outs := make(map[string][]byte)
+	fset := cg.p.Pkg().Fset
+	testPkgFile := testFileName(pkgFile)
+
+	var errs error
+	for _, file := range files {
+		name := filepath.Base(fset.File(file.Pos()).Name())
+		if out := genFileName(name); out == pkgFile || out == testPkgFile {
+			errs = errors.Join(errs, codefmt.Errorf(cg.p, file.Name, "cannot generate %s from %s in split mode: conflicts with the file for implicit converters; rename %s",
+				out, name, name))
+		}
+	}
+	if errs != nil {
+		return nil, errs
+	}
+
+	for _, file := range files {
+		tokFile := fset.File(file.Pos())
+
+		var convs []assign.Conv
+		for _, conv := range cg.sortedConvs() {
+			if fset.File(conv.Pos()) == tokFile {
+				convs = append(convs, conv)
 			}
-			return 0
-		})
+		}
 
-		for _, conv := range convs {
-			local := maps.Clone(cg.ns)
-			w := cg.w.WithNS(local)
-			conv.WriteDefineCode(w)
-			cg.w.Printf("\n")
+		var buf bytes.Buffer
+		w := cg.w.ForFile(&buf)
+		cg.writeExplicitConvs(w, convs)
+		cg.mergeCode(w, &buf, file)
+		outs[genFileName(filepath.Base(tokFile.Name()))] = cg.frameCode(w, &buf)
+	}
+
+	// Split modules by whether they are declared in test files.
+	var mods, testMods []*parse.Module
+	for _, mod := range cg.sortedMods() {
+		if strings.HasSuffix(fset.Position(cg.modulePos(mod)).Filename, "_test.go") {
+			testMods = append(testMods, mod)
+		} else {
+			mods = append(mods, mod)
 		}
 	}
 
-	subconvCount := 0
-	for _, subconvs := range cg.subconvs {
-		subconvCount += len(subconvs)
+	for _, out := range []struct {
+		name string
+		mods []*parse.Module
+	}{{pkgFile, mods}, {testPkgFile, testMods}} {
+		var buf bytes.Buffer
+		w := cg.w.ForFile(&buf)
+		cg.writeImplicitConvs(w, out.mods)
+		if out.name == testPkgFile && roundTrip {
+			cg.writeRoundTripTests(w)
+		}
+
+		if buf.Len() != 0 {
+			outs[out.name] = cg.frameCode(w, &buf)
+		}
 	}
-	if subconvCount != 0 {
-		cg.w.Printf("// convgen: implicit converters\n\n")
+	return outs, nil
+}
 
-		sortedMods := slices.Collect(maps.Keys(cg.subconvs))
-		slices.SortFunc(sortedMods, func(a, b *parse.Module) int {
-			if a.Name < b.Name {
-				return -1
-			}
-			if a.Name > b.Name {
-				return 1
-			}
-			return 0
-		})
+// testFileName returns the name of the test file for the output file.
+//
+// e.g., "convgen_gen.go" => "convgen_gen_test.go"
+func testFileName(name string) string {
+	return strings.TrimSuffix(name, ".go") + "_test.go"
+}
 
-		for _, mod := range sortedMods {
-			for _, conv := range cg.subconvs[mod] {
-				local := maps.Clone(cg.ns)
-				w := cg.w.WithNS(local)
-				conv.WriteDefineCode(w)
-				cg.w.Printf("\n")
-			}
+// genFileName returns the name of the file generated from the Convgen file.
+// Test files keep the "_test.go" suffix to be built only in tests.
+//
+// e.g., "user.go" => "user_gen.go", "user_test.go" => "user_gen_test.go"
+func genFileName(name string) string {
+	if base, ok := strings.CutSuffix(name, "_test.go"); ok {
+		return base + "_gen_test.go"
+	}
+	return strings.TrimSuffix(name, ".go") + "_gen.go"
+}
+
+// srcFileName is the inverse of [genFileName]. It reports false if the name
+// is not generated from a Convgen file.
+//
+// e.g., "user_gen.go" => "user.go", "user_gen_test.go" => "user_test.go"
+func srcFileName(name string) (string, bool) {
+	if base, ok := strings.CutSuffix(name, "_gen_test.go"); ok {
+		return base + "_test.go", true
+	}
+	if base, ok := strings.CutSuffix(name, "_gen.go"); ok {
+		return base + ".go", true
+	}
+	return "", false
+}
+
+// sortedConvs returns explicit converters sorted by their positions.
+func (cg *Convgen) sortedConvs() []assign.Conv {
+	convs := slices.Collect(maps.Values(cg.convs))
+	slices.SortFunc(convs, func(a, b assign.Conv) int {
+		if a.Pos() < b.Pos() {
+			return -1
+		}
+		if a.Pos() > b.Pos() {
+			return 1
+		}
+		return 0
+	})
+	return convs
+}
+
+// sortedMods returns modules which have implicit converters sorted by their
+// names.
+func (cg *Convgen) sortedMods() []*parse.Module {
+	var mods []*parse.Module
+	for mod, subconvs := range cg.subconvs {
+		if len(subconvs) != 0 {
+			mods = append(mods, mod)
 		}
 	}
+	slices.SortFunc(mods, func(a, b *parse.Module) int {
+		if a.Name < b.Name {
+			return -1
+		}
+		if a.Name > b.Name {
+			return 1
+		}
+		return 0
+	})
+	return mods
 }
 
-// mergeCode copies non-convgen code from the source files that tagged with
+// modulePos returns the position where the module is declared. For a module
+// without declaration, it returns the position of the first converter using
+// the module.
+func (cg *Convgen) modulePos(mod *parse.Module) token.Pos {
+	for pos, m := range cg.mods {
+		if m == mod {
+			return pos
+		}
+	}
+
+	pos := token.NoPos
+	for _, inj := range cg.injs {
+		if inj.Module == mod && (!pos.IsValid() || inj.Pos() < pos) {
+			pos = inj.Pos()
+		}
+	}
+	return pos
+}
+
+// writeExplicitConvs writes function declaration code for explicit converters.
+func (cg *Convgen) writeExplicitConvs(w *codefmt.Writer, convs []assign.Conv) {
+	if len(convs) == 0 {
+		return
+	}
+
+	w.Printf("// convgen: explicit converters\n\n")
+	for _, conv := range convs {
+		local := maps.Clone(cg.ns)
+		conv.WriteDefineCode(w.WithNS(local))
+		w.Printf("\n")
+	}
+}
+
+// writeImplicitConvs writes function declaration code for implicit converters
+// of the modules.
+func (cg *Convgen) writeImplicitConvs(w *codefmt.Writer, mods []*parse.Module) {
+	if len(mods) == 0 {
+		return
+	}
+
+	w.Printf("// convgen: implicit converters\n\n")
+	for _, mod := range mods {
+		for _, conv := range cg.subconvs[mod] {
+			local := maps.Clone(cg.ns)
+			conv.WriteDefineCode(w.WithNS(local))
+			w.Printf("\n")
+		}
+	}
+}
+
+// mergeCode copies non-convgen code from the source file that tagged with
 // "//go:build convgen". It erases convgen directives to remove any references
 // to the convgen package.
-func (cg *Convgen) mergeCode() {
-	for _, file := range cg.p.ConvgenGoFiles() {
-		name := filepath.Base(cg.p.Pkg().Fset.File(file.Pos()).Name())
-		first := true
-
-		for _, decl := range file.Decls {
-			if gen, ok := decl.(*ast.GenDecl); ok {
-				if gen.Tok == token.IMPORT {
-					// Skip import declarations in files. Required imports will
-					// be collected from their usage, and then rewritten as an
-					// import declaration group.
-					continue
-				}
+func (cg *Convgen) mergeCode(w *codefmt.Writer, buf *bytes.Buffer, file *ast.File) {
+	name := filepath.Base(cg.p.Pkg().Fset.File(file.Pos()).Name())
+	first := true
+
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok {
+			if gen.Tok == token.IMPORT {
+				// Skip import declarations in files. Required imports will be
+				// collected from their usage, and then rewritten as an import
+				// declaration group.
+				continue
 			}
+		}
 
-			if first {
-				fmt.Fprintf(cg.buf, "// %s:\n\n", name)
-				first = false
-			}
+		if first {
+			fmt.Fprintf(buf, "// %s:\n\n", name)
+			first = false
+		}
 
-			// Erase Convgen directives
-			decl = cg.eraseDirectives(decl)
+		// Erase Convgen directives
+		decl = cg.eraseDirectives(decl)
 
-			// Skip empty declarations
-			if gen, ok := decl.(*ast.GenDecl); ok {
-				if len(gen.Specs) == 0 {
-					continue
-				}
+		// Skip empty declarations
+		if gen, ok := decl.(*ast.GenDecl); ok {
+			if len(gen.Specs) == 0 {
+				continue
 			}
+		}
 
-			// Prevent import name conflicts when merging multiple files into one
-			decl = codefmt.RewriteImports(cg.w, decl)
+		// Prevent import name conflicts when merging multiple files into one
+		decl = codefmt.RewriteImports(w, decl)
 
-			// Write rewritten declaration code
-			printer.Fprint(cg.buf, cg.p.Pkg().Fset, &printer.CommentedNode{
-				Node:     decl,
-				Comments: file.Comments,
-			})
-			fmt.Fprintf(cg.buf, "\n\n")
-		}
+		// Write rewritten declaration code
+		printer.Fprint(buf, cg.p.Pkg().Fset, &printer.CommentedNode{
+			Node:     decl,
+			Comments: file.Comments,
+		})
+		fmt.Fprintf(buf, "\n\n")
 	}
 }
 
@@ -300,7 +491,24 @@ func (cg *Convgen) eraseDirectives(decl ast.Decl) ast.Decl {
 	return decl
 }
 
-func (cg *Convgen) frameCode() []byte {
+// generatedHeader is the prefix of the comment line which marks files generated
+// by Convgen.
+const generatedHeader = "// Code generated by github.com/sublee/convgen"
+
+// IsGenerated reports whether the code is generated by Convgen.
+func IsGenerated(code []byte) bool {
+	for line := range bytes.Lines(code) {
+		if bytes.HasPrefix(line, []byte(generatedHeader)) {
+			return true
+		}
+		if bytes.HasPrefix(line, []byte("package ")) {
+			break
+		}
+	}
+	return false
+}
+
+func (cg *Convgen) frameCode(w *codefmt.Writer, body *bytes.Buffer) []byte {
 	// Prepend header code
 	versionSuffix := ""
 	if Version != "" {
@@ -309,12 +517,12 @@ func (cg *Convgen) frameCode() []byte {
 
 	var buf bytes.Buffer
 	fmt.Fprintf(&buf, "//go:build !convgen\n")
-	fmt.Fprintf(&buf, "// Code generated by github.com/sublee/convgen%s. DO NOT EDIT.\n", versionSuffix)
+	fmt.Fprintf(&buf, "%s%s. DO NOT EDIT.\n", generatedHeader, versionSuffix)
 	fmt.Fprintf(&buf, "package %s\n", cg.p.Pkg().Name)
 
-	if len(cg.w.Imports()) != 0 {
+	if imports := w.Imports(); len(imports) != 0 {
 		fmt.Fprintf(&buf, "import (\n")
-		for alias, imp := range cg.w.Imports() {
+		for alias, imp := range imports {
 			// Check for remaining convgen import
 			if imp.Path() == "github.com/sublee/convgen" {
 				fmt.Println("convgen import remains")
@@ -329,7 +537,7 @@ func (cg *Convgen) frameCode() []byte {
 		fmt.Fprintf(&buf, ")\n")
 	}
 
-	_, _ = io.Copy(&buf, cg.buf)
+	_, _ = io.Copy(&buf, body)
 	code := buf.Bytes()
 
 	// Apply gofmt if succeeded
@@ -0,0 +1,30 @@
+package convgeninternal
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/sublee/convgen/internal/codefmt"
+	"github.com/sublee/convgen/internal/convgen/assign"
+)
+
+// Explain returns the explanations of the explicit converters with the given
+// name as informational diagnostics. Each explanation shows the full match
+// table of the converter and its implicit subconverters, even though matching
+// succeeded. It returns nil if there is no such converter. It must be called
+// after [Convgen.Build] succeeds.
+func (cg *Convgen) Explain(name string) error {
+	var infos error
+	for _, conv := range cg.sortedConvs() {
+		inj := cg.injs[conv.Pos()]
+		if inj.Name() != name {
+			continue
+		}
+
+		table := strings.ReplaceAll(assign.Explain(conv), "\n", "\n\t")
+		table = strings.ReplaceAll(table, "\n\t\n", "\n\n")
+		info := codefmt.Infof(cg.p, inj, "explain %s: %t -> %t\n\t%s", name, inj.X(), inj.Y(), table)
+		infos = errors.Join(infos, codefmt.WithConverter(info, name))
+	}
+	return infos
+}
@@ -0,0 +1,371 @@
+package convgeninternal
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sublee/convgen/internal/codefmt"
+	"github.com/sublee/convgen/internal/convgen/assign"
+	"github.com/sublee/convgen/internal/typeinfo"
+)
+
+// graph is the dependency graph of the converters in a package. Nodes are
+// explicit converters, implicit subconverters, imported functions, plugins,
+// and error wrappers. Edges are calls between them in the generated code.
+type graph struct {
+	Package string      `json:"package"`
+	Nodes   []graphNode `json:"nodes"`
+	Edges   []graphEdge `json:"edges"`
+}
+
+// graphNode is a node of [graph]. Its ID is the name of the converter or
+// function, or a position for a function literal.
+type graphNode struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+	X    string `json:"x,omitempty"`
+	Y    string `json:"y,omitempty"`
+	Pos  string `json:"pos,omitempty"`
+
+	// Err reports whether the node may return an error.
+	Err bool `json:"err,omitempty"`
+
+	// Cyclic reports whether the node calls itself directly or indirectly.
+	Cyclic bool `json:"cyclic,omitempty"`
+
+	// CreatedBy is the explicit converter which created the subconverter.
+	// Other converters reuse the subconverter instead of creating a new one.
+	CreatedBy string `json:"createdBy,omitempty"`
+
+	// ErrPath is the path of calls from the subconverter to the node which
+	// forced the subconverter to return an error.
+	ErrPath []string `json:"errPath,omitempty"`
+}
+
+// graphEdge is an edge of [graph]. X and Y are the pair converted by the call.
+type graphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	X    string `json:"x,omitempty"`
+	Y    string `json:"y,omitempty"`
+
+	// Reused reports whether the callee is a subconverter created by another
+	// explicit converter.
+	Reused bool `json:"reused,omitempty"`
+
+	// Cyclic reports whether the edge is in a cycle.
+	Cyclic bool `json:"cyclic,omitempty"`
+
+	// ErrPath reports whether the edge is in the ErrPath of a node.
+	ErrPath bool `json:"errPath,omitempty"`
+}
+
+// Graph returns the dependency graph of the explicit and implicit converters.
+// It must be called after [Convgen.Build] succeeds.
+func (cg *Convgen) Graph() *graph {
+	g := &graph{Package: cg.p.Pkg().PkgPath, Nodes: []graphNode{}, Edges: []graphEdge{}}
+	index := make(map[string]int)
+	addNode := func(n graphNode) {
+		if _, ok := index[n.ID]; !ok {
+			index[n.ID] = len(g.Nodes)
+			g.Nodes = append(g.Nodes, n)
+		}
+	}
+
+	type caller struct {
+		id, root string
+		conv     assign.Conv
+	}
+	var callers []caller
+	for _, conv := range cg.sortedConvs() {
+		inj := cg.injs[conv.Pos()]
+		addNode(graphNode{
+			ID:   inj.Name(),
+			Kind: "converter",
+			X:    codefmt.FormatType(cg.p, inj.X().Type()),
+			Y:    codefmt.FormatType(cg.p, inj.Y().Type()),
+			Pos:  cg.formatPos(conv.Pos()),
+			Err:  inj.HasErr(),
+		})
+		callers = append(callers, caller{inj.Name(), inj.Name(), conv})
+	}
+	for _, mod := range cg.sortedMods() {
+		for _, conv := range cg.subconvs[mod] {
+			m := assign.Describe(conv)
+			addNode(graphNode{
+				ID:        m.Name,
+				Kind:      "subconverter",
+				X:         codefmt.FormatType(cg.p, m.X),
+				Y:         codefmt.FormatType(cg.p, m.Y),
+				CreatedBy: cg.creators[conv],
+			})
+			callers = append(callers, caller{m.Name, cg.creators[conv], conv})
+		}
+	}
+
+	for _, c := range callers {
+		for _, call := range assign.Calls(c.conv) {
+			id := cg.calleeID(call.Callee)
+			if n, ok := index[id]; ok && g.Nodes[n].Kind == "subconverter" {
+				// A subconverter created by another converter is called
+				// as a function.
+				call.Callee.Kind = "subconverter"
+			}
+			addNode(graphNode{ID: id, Kind: call.Callee.Kind})
+
+			n := &g.Nodes[index[id]]
+			n.Err = n.Err || call.Callee.Err
+
+			reused := call.Callee.Kind == "subconverter" && n.CreatedBy != c.root
+			g.Edges = append(g.Edges, graphEdge{From: c.id, To: id, X: call.X, Y: call.Y, Reused: reused})
+		}
+	}
+
+	g.markCycles()
+	g.markErrPaths()
+	return g
+}
+
+// calleeID returns the node ID of the callee.
+func (cg *Convgen) calleeID(callee assign.Callee) string {
+	switch {
+	case callee.Kind == "plugin":
+		return "plugin " + callee.Name
+	case callee.Name != "":
+		return callee.Name
+	}
+	return cg.funcID(callee.Func)
+}
+
+// funcID returns the node ID of a declared function or a function literal.
+func (cg *Convgen) funcID(fn typeinfo.Func) string {
+	if fn.Name() == "" {
+		return "func literal at " + cg.formatPos(fn.Pos())
+	}
+	if fn.Object().Pkg() == cg.p.Pkg().Types {
+		return fn.Name()
+	}
+	if f, ok := fn.Object().(*types.Func); ok {
+		return f.FullName()
+	}
+	return codefmt.FormatObj(cg.p, fn.Object())
+}
+
+// markCycles marks the nodes and edges in cycles by finding the strongly
+// connected components with Tarjan's algorithm.
+func (g *graph) markCycles() {
+	succs := make(map[string][]string)
+	for _, e := range g.Edges {
+		succs[e.From] = append(succs[e.From], e.To)
+	}
+
+	var (
+		counter int
+		stack   []string
+		index   = make(map[string]int)
+		low     = make(map[string]int)
+		onStack = make(map[string]bool)
+		comp    = make(map[string]int)
+		cyclic  = make(map[string]bool)
+	)
+	var visit func(v string)
+	visit = func(v string) {
+		counter++
+		index[v], low[v] = counter, counter
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range succs[v] {
+			if index[w] == 0 {
+				visit(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], index[w])
+			}
+		}
+
+		if low[v] == index[v] {
+			var members []string
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				comp[w] = index[v]
+				members = append(members, w)
+				if w == v {
+					break
+				}
+			}
+			if len(members) > 1 || slices.Contains(succs[v], v) {
+				for _, w := range members {
+					cyclic[w] = true
+				}
+			}
+		}
+	}
+	for _, n := range g.Nodes {
+		if index[n.ID] == 0 {
+			visit(n.ID)
+		}
+	}
+
+	for i := range g.Nodes {
+		g.Nodes[i].Cyclic = cyclic[g.Nodes[i].ID]
+	}
+	for i := range g.Edges {
+		e := &g.Edges[i]
+		e.Cyclic = cyclic[e.From] && comp[e.From] == comp[e.To]
+	}
+}
+
+// markErrPaths finds the ErrPath of each subconverter which may return an
+// error. It follows calls to callees which may return an error until it
+// reaches one which is not a subconverter, such as a function imported by
+// convgen.ImportFuncErr.
+func (g *graph) markErrPaths() {
+	nodes := make(map[string]*graphNode)
+	for i := range g.Nodes {
+		nodes[g.Nodes[i].ID] = &g.Nodes[i]
+	}
+
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		if n.Kind != "subconverter" || !n.Err {
+			continue
+		}
+
+		// Breadth-first search to find the shortest path.
+		prev := map[string]string{n.ID: ""}
+		queue := []string{n.ID}
+		var cause string
+		for len(queue) != 0 && cause == "" {
+			v := queue[0]
+			queue = queue[1:]
+			for _, e := range g.Edges {
+				to := nodes[e.To]
+				if e.From != v || !to.Err || to.Kind == "errwrap" {
+					continue
+				}
+				if _, ok := prev[e.To]; ok {
+					continue
+				}
+				prev[e.To] = v
+				if to.Kind != "subconverter" {
+					cause = e.To
+					break
+				}
+				queue = append(queue, e.To)
+			}
+		}
+		if cause == "" {
+			continue
+		}
+
+		for v := cause; v != ""; v = prev[v] {
+			n.ErrPath = append(n.ErrPath, v)
+		}
+		slices.Reverse(n.ErrPath)
+		for j := range g.Edges {
+			e := &g.Edges[j]
+			if k := slices.Index(n.ErrPath, e.From); k != -1 && k+1 < len(n.ErrPath) && n.ErrPath[k+1] == e.To {
+				e.ErrPath = true
+			}
+		}
+	}
+}
+
+// encodeGraphs encodes the graphs of packages in the format, "dot" or "json".
+func encodeGraphs(format string, graphs []*graph) ([]byte, error) {
+	switch format {
+	case "json":
+		if graphs == nil {
+			graphs = []*graph{}
+		}
+		data, err := json.MarshalIndent(graphs, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case "dot":
+		return encodeDOT(graphs), nil
+	}
+	return nil, fmt.Errorf("unknown graph format %q", format)
+}
+
+// encodeDOT encodes the graphs in the DOT language of Graphviz. Each package
+// is a cluster. Cycles are red and the paths which forced subconverters to
+// return an error are orange.
+func encodeDOT(graphs []*graph) []byte {
+	var b strings.Builder
+	b.WriteString("digraph convgen {\n")
+	b.WriteString("\tnode [fontname=monospace];\n")
+	b.WriteString("\tedge [fontname=monospace];\n")
+
+	for _, g := range graphs {
+		id := func(name string) string { return strconv.Quote(g.Package + "." + name) }
+
+		fmt.Fprintf(&b, "\tsubgraph %s {\n", strconv.Quote("cluster_"+g.Package))
+		fmt.Fprintf(&b, "\t\tlabel=%s;\n", strconv.Quote(g.Package))
+
+		for _, n := range g.Nodes {
+			label := n.ID
+			if n.X != "" {
+				label += "\n" + n.X + " -> " + n.Y
+			}
+			if n.CreatedBy != "" {
+				label += "\ncreated by " + n.CreatedBy
+			}
+
+			attrs := []string{"label=" + strconv.Quote(label)}
+			switch n.Kind {
+			case "converter":
+				attrs = append(attrs, "shape=box")
+			case "subconverter":
+				attrs = append(attrs, "shape=box", "style=dashed")
+			case "plugin":
+				attrs = append(attrs, "shape=component")
+			case "errwrap":
+				attrs = append(attrs, "shape=note")
+			}
+			if n.Cyclic {
+				attrs = append(attrs, "color=red")
+			} else if n.Err {
+				attrs = append(attrs, "color=orange")
+			}
+			fmt.Fprintf(&b, "\t\t%s [%s];\n", id(n.ID), strings.Join(attrs, ", "))
+		}
+
+		for _, e := range g.Edges {
+			var attrs []string
+			if e.X != "" {
+				label := e.X + " -> " + e.Y
+				if e.Reused {
+					label += "\n(reused)"
+				}
+				attrs = append(attrs, "label="+strconv.Quote(label))
+			}
+			if e.Reused {
+				attrs = append(attrs, "style=dashed")
+			}
+			if e.Cyclic {
+				attrs = append(attrs, "color=red")
+			} else if e.ErrPath {
+				attrs = append(attrs, "color=orange")
+			}
+
+			fmt.Fprintf(&b, "\t\t%s -> %s", id(e.From), id(e.To))
+			if len(attrs) != 0 {
+				fmt.Fprintf(&b, " [%s]", strings.Join(attrs, ", "))
+			}
+			b.WriteString(";\n")
+		}
+		b.WriteString("\t}\n")
+	}
+
+	b.WriteString("}\n")
+	return []byte(b.String())
+}
@@ -0,0 +1,208 @@
+package convgeninternal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// load loads packages. Only the target packages are parsed and type-checked
+// from source. Their dependencies are imported from export data, which is much
+// faster than type-checking the whole dependency graph from source.
+//
+// If the fast path fails for any reason, such as errors in the packages or
+// export data which cannot be read, it falls back to [loadFromSource] to
+// report errors in the same way.
+//
+// If some packages have errors, it returns the loaded packages with the
+// errors.
+func load(ctx context.Context, opts Options, patterns []string) ([]*packages.Package, error) {
+	cfg := &packages.Config{
+		Mode:       packages.NeedCompiledGoFiles | packages.NeedDeps | packages.NeedExportFile | packages.NeedFiles | packages.NeedImports | packages.NeedModule | packages.NeedName | packages.NeedTypesSizes,
+		Context:    ctx,
+		Dir:        opts.WD,
+		Env:        opts.Env,
+		BuildFlags: buildFlags(opts.Tags),
+		Tests:      opts.Tests,
+		Overlay:    opts.Overlay,
+	}
+
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages: %w", err)
+	}
+
+	fset := token.NewFileSet()
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) != 0 || !typeCheck(fset, pkg, opts.Overlay) {
+			return loadFromSource(ctx, opts, patterns)
+		}
+	}
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("no packages found: %v", patterns)
+	}
+	return pkgs, nil
+}
+
+// typeCheck parses and type-checks the package from source with its
+// dependencies from export data. Files in the overlay are parsed from the
+// overlay contents. It reports whether it succeeded without any error.
+func typeCheck(fset *token.FileSet, pkg *packages.Package, overlay map[string][]byte) bool {
+	var files []*ast.File
+	for _, path := range pkg.CompiledGoFiles {
+		var src any
+		if content, ok := overlay[path]; ok {
+			src = content
+		}
+
+		file, err := parser.ParseFile(fset, path, src, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return false
+		}
+		files = append(files, file)
+	}
+
+	// Export data refers to packages by their paths. A path is unique in the
+	// dependency graph of a package, but not across the graphs of a package
+	// and its test variant.
+	exports := make(map[string]string)
+	packages.Visit([]*packages.Package{pkg}, nil, func(dep *packages.Package) {
+		exports[dep.PkgPath] = dep.ExportFile
+	})
+	lookup := func(path string) (io.ReadCloser, error) {
+		// Import paths in the source may differ from the package paths, such
+		// as vendored packages.
+		if imp, ok := pkg.Imports[path]; ok {
+			path = imp.PkgPath
+		}
+		export := exports[path]
+		if export == "" {
+			return nil, fmt.Errorf("no export data for %q", path)
+		}
+		return os.Open(export)
+	}
+
+	// The sizes are of the target platform which the go command reports with
+	// the environment, not of the platform running Convgen.
+	cfg := &types.Config{
+		Importer: importer.ForCompiler(fset, "gc", lookup),
+		Sizes:    pkg.TypesSizes,
+		Error:    func(error) {},
+	}
+	if pkg.Module != nil && pkg.Module.GoVersion != "" {
+		cfg.GoVersion = "go" + pkg.Module.GoVersion
+	}
+
+	info := &types.Info{
+		Types:        make(map[ast.Expr]types.TypeAndValue),
+		Defs:         make(map[*ast.Ident]types.Object),
+		Uses:         make(map[*ast.Ident]types.Object),
+		Implicits:    make(map[ast.Node]types.Object),
+		Instances:    make(map[*ast.Ident]types.Instance),
+		Scopes:       make(map[ast.Node]*types.Scope),
+		Selections:   make(map[*ast.SelectorExpr]*types.Selection),
+		FileVersions: make(map[*ast.File]string),
+	}
+	tpkg, err := cfg.Check(pkg.PkgPath, fset, files, info)
+	if err != nil {
+		return false
+	}
+
+	pkg.Fset = fset
+	pkg.Syntax = files
+	pkg.Types = tpkg
+	pkg.TypesInfo = info
+	return true
+}
+
+// loadFromSource loads packages with their dependencies type-checked from
+// source. It is slow but reports errors in detail.
+func loadFromSource(ctx context.Context, opts Options, patterns []string) ([]*packages.Package, error) {
+	cfg := &packages.Config{
+		Mode:       packages.NeedDeps | packages.NeedFiles | packages.NeedImports | packages.NeedModule | packages.NeedName | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo,
+		Context:    ctx,
+		Dir:        opts.WD,
+		Env:        opts.Env,
+		BuildFlags: buildFlags(opts.Tags),
+		Tests:      opts.Tests,
+		Overlay:    opts.Overlay,
+	}
+
+	// Load the packages based on the provided patterns.
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages: %w", err)
+	}
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("no packages found: %v", patterns)
+	}
+
+	// Check for errors in the loaded packages.
+	var errs error
+	for _, pkg := range pkgs {
+		for _, err := range pkg.Errors {
+			if err.Pos == "" {
+				errs = errors.Join(errs, errors.New(err.Msg))
+				continue
+			}
+
+			path, rowcol, _ := strings.Cut(err.Pos, ":")
+			if rel, relErr := filepath.Rel(opts.WD, path); relErr == nil {
+				err.Pos = rel + ":" + rowcol
+			}
+			errs = errors.Join(errs, err)
+		}
+	}
+	return pkgs, errs
+}
+
+// buildFlags returns the build flags to load packages with the convgen build
+// tag and the extra tags.
+func buildFlags(tags string) []string {
+	if tags != "" {
+		return []string{"-tags=convgen," + tags}
+	}
+	return []string{"-tags=convgen"}
+}
+
+// BuildContext returns the build context which selects the same files as the
+// go command does with the environment and the extra build tags, including
+// GOOS, GOARCH, CGO_ENABLED, and the -tags flag in GOFLAGS. The convgen build
+// tag is always set. If env is nil, it follows the environment of the current
+// process.
+func BuildContext(env []string, tags string) build.Context {
+	ctxt := build.Default
+	if v := lookupEnv(env, "GOOS"); v != "" {
+		ctxt.GOOS = v
+	}
+	if v := lookupEnv(env, "GOARCH"); v != "" {
+		ctxt.GOARCH = v
+	}
+	if v := lookupEnv(env, "CGO_ENABLED"); v != "" {
+		ctxt.CgoEnabled = v == "1"
+	}
+
+	ctxt.BuildTags = append(slices.Clone(ctxt.BuildTags), "convgen")
+	for _, flag := range strings.Fields(lookupEnv(env, "GOFLAGS")) {
+		if v, ok := strings.CutPrefix(strings.TrimLeft(flag, "-"), "tags="); ok {
+			ctxt.BuildTags = append(ctxt.BuildTags, strings.Split(v, ",")...)
+		}
+	}
+	if tags != "" {
+		ctxt.BuildTags = append(ctxt.BuildTags, strings.Split(tags, ",")...)
+	}
+	return ctxt
+}
@@ -4,120 +4,483 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"maps"
+	"os"
 	"path/filepath"
 	"slices"
 	"sort"
-	"strings"
+	"sync"
 
 	"golang.org/x/tools/go/packages"
+
+	"github.com/sublee/convgen/internal/convgen/parse"
 )
 
 var Version string
 
+// Options configures [Main].
+type Options struct {
+	// WD is the path of the working directory.
+	WD string
+
+	// Env is the environment variables to use when loading packages.
+	Env []string
+
+	// Tags is the comma-separated build tags to use when loading packages.
+	Tags string
+
+	// Tests indicates whether to include test files.
+	Tests bool
+
+	// OutFile is the name of the output file to generate in each package. In
+	// split mode, it holds implicit converters shared by the package.
+	OutFile string
+
+	// Split generates one output file per Convgen file instead of merging them
+	// into OutFile. See [Convgen.GenerateFiles].
+	Split bool
+
+	// Patterns are the package patterns to process.
+	Patterns []string
+
+	// Strict makes warnings fail the generation as errors.
+	Strict bool
+
+	// Overlay maps absolute file paths to their contents to use instead of the
+	// files on disk. The cache is disabled if it is not empty.
+	Overlay map[string][]byte
+
+	// Jobs is the maximum number of packages to build concurrently. If it is
+	// less than 1, packages are built one by one.
+	Jobs int
+
+	// CacheDir is the directory to cache generated files of packages. If it is
+	// empty, the cache is disabled.
+	CacheDir string
+
+	// Explain is the name of converters to explain. See [Convgen.Explain]. The
+	// cache is disabled if it is not empty.
+	Explain string
+
+	// RoundTrip generates round-trip tests and fuzz targets for pairs of
+	// explicit struct converters which convert types to each other in the
+	// "_test.go" variant of OutFile. See [Convgen.GenerateRoundTripTests].
+	RoundTrip bool
+
+	// Manifest is the format of the mapping manifest to write next to OutFile
+	// in each package, "json" or "yaml". If it is empty, no manifest is
+	// written. See [Convgen.Manifest].
+	Manifest string
+
+	// Graph is the format of the dependency graph of converters, "dot" or
+	// "json". If it is not empty, [Result.Graph] holds the graph of all
+	// packages and the cache is disabled.
+	Graph string
+}
+
+// Result is the result of [Main].
+type Result struct {
+	// Files maps output file paths to their contents.
+	Files map[string][]byte
+
+	// Orphans are paths of files previously generated by Convgen in packages
+	// which no longer have Convgen files, even ones which are not loaded. In
+	// split mode, they also include files generated from removed Convgen
+	// files.
+	Orphans []string
+
+	// Warnings holds warnings which did not prevent the generation. It is nil
+	// if there is no warning or Strict is set.
+	Warnings error
+
+	// Infos holds the informational diagnostics, such as the explanations of
+	// converters requested by Explain.
+	Infos error
+
+	// Graph is the dependency graph of converters in the format of
+	// [Options.Graph]. It is nil if Graph is empty.
+	Graph []byte
+
+	// Packages maps IDs of packages to the paths of files generated for them.
+	Packages map[string][]string
+
+	// CacheHits and CacheMisses are the IDs of packages found and not found in
+	// the cache. They are empty if the cache is disabled.
+	CacheHits, CacheMisses []string
+}
+
 // Main is the main entry point for Convgen. It is used by the command-line tool
 // directly.
 //
 // ctx is the context for loading packages. If the loading is too slow, ctx can
-// cancel the operation. wd is the path of the working directory. env is the
-// environment variables to use when running the tool. tags is the build tags to
-// use when loading packages. tests indicates whether to include test files.
-// outFile is the name of the output file to generate in each package. And
-// patterns are the package patterns to process.
+// cancel the operation.
 //
-// It returns a map of output file paths to their contents. If any error occurs,
-// it returns a non-nil error.
-func Main(ctx context.Context, wd string, env []string, tags string, tests bool, outFile string, patterns []string) (map[string][]byte, error) {
-	pkgs, err := load(ctx, wd, env, tags, tests, patterns)
-	if err != nil {
-		return nil, err
+// It returns the generated files and warnings. If any error occurs, it returns
+// a non-nil error. In strict mode, warnings are returned as errors too.
+func Main(ctx context.Context, opts Options) (Result, error) {
+	if opts.CacheDir != "" && len(opts.Overlay) == 0 && opts.Explain == "" && opts.Graph == "" {
+		return mainCached(ctx, opts)
 	}
 
-	outs := make(map[string][]byte)
-	var errs error
+	pkgs, err := load(ctx, opts, opts.Patterns)
+	if err != nil {
+		return Result{}, err
+	}
+	return generate(pkgs, opts)
+}
 
-	for _, pkg := range pkgs {
+// mainCached is [Main] with the cache. It loads packages cheaply to compute
+// their cache keys first. Then it loads and builds only the packages missing
+// in the cache.
+func mainCached(ctx context.Context, opts Options) (Result, error) {
+	lites, err := loadExports(ctx, opts)
+	if err != nil {
+		return Result{}, err
+	}
+	for _, pkg := range lites {
 		if len(pkg.Errors) != 0 {
-			err := fmt.Errorf("pkg %q has errors", pkg.Name)
-			errs = errors.Join(errs, err)
+			// Fall back to the full loading to report errors.
+			opts.CacheDir = ""
+			return Main(ctx, opts)
+		}
+	}
+
+	g := newGenerator(opts, newCache(opts.CacheDir))
+	var dirs []string
+	for _, pkg := range lites {
+		if len(pkg.GoFiles) == 0 {
 			continue
 		}
+		dir := filepath.Dir(pkg.GoFiles[0])
 
-		cg, err := New(pkg)
+		key, ok := g.cache.key(pkg, opts)
+		if ok {
+			if files, ok := g.cache.get(key); ok && g.checkOverwrite(dir, files) == nil {
+				g.add(pkg.ID, dir, files)
+				g.hits = append(g.hits, pkg.ID)
+				continue
+			}
+			g.cache.keys[pkg.ID] = key
+		}
+
+		g.misses = append(g.misses, pkg.ID)
+		if !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	// Load the packages missing in the cache by their directories. It loads
+	// test variants of a package together.
+	var pkgs []*packages.Package
+	if len(dirs) != 0 {
+		pkgs, err = load(ctx, opts, dirs)
 		if err != nil {
-			errs = errors.Join(errs, err)
-			continue
+			return Result{CacheHits: g.hits, CacheMisses: g.misses}, err
 		}
+	}
+	return g.generate(pkgs)
+}
 
-		if err := cg.Build(); err != nil {
-			errs = errors.Join(errs, err)
+// generate builds converters and generates code for the loaded packages.
+func generate(pkgs []*packages.Package, opts Options) (Result, error) {
+	return newGenerator(opts, nil).generate(pkgs)
+}
+
+// generator collects files generated for packages.
+type generator struct {
+	opts  Options
+	cache *cache // nil if the cache is disabled
+
+	files        map[string][]byte
+	pkgs         map[string][]string
+	orphans      []string
+	hits, misses []string
+}
+
+func newGenerator(opts Options, c *cache) *generator {
+	return &generator{
+		opts:  opts,
+		cache: c,
+		files: make(map[string][]byte),
+		pkgs:  make(map[string][]string),
+	}
+}
+
+// generate builds converters and generates code for the loaded packages. The
+// files generated so far are included in the result.
+func (g *generator) generate(pkgs []*packages.Package) (Result, error) {
+	// Build packages concurrently. Then collect their results in order to keep
+	// the result deterministic.
+	results := make([]pkgResult, len(pkgs))
+	sem := make(chan struct{}, max(g.opts.Jobs, 1))
+	var wg sync.WaitGroup
+	for i, pkg := range pkgs {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = g.build(pkg)
+		})
+	}
+	wg.Wait()
+
+	var errs, warns, infos error
+	var graphs []*graph
+	for i, pkg := range pkgs {
+		r := results[i]
+		if r.err != nil {
+			errs = errors.Join(errs, r.err)
 			continue
 		}
+		warns = errors.Join(warns, r.warns)
+		infos = errors.Join(infos, r.infos)
+		if r.graph != nil {
+			graphs = append(graphs, r.graph)
+		}
 
-		code := cg.Generate()
-		if len(code) == 0 {
+		if len(pkg.GoFiles) == 0 {
 			continue
 		}
+		g.add(pkg.ID, filepath.Dir(pkg.GoFiles[0]), r.files)
 
-		outDir := filepath.Dir(pkg.GoFiles[0])
-		if rel, err := filepath.Rel(wd, outDir); err == nil {
-			outDir = rel
+		// Packages with warnings are not cached to report the warnings again.
+		if g.cache != nil && r.warns == nil {
+			g.cache.put(pkg, r.files)
 		}
-		out := filepath.Join(outDir, outFile)
-		outs[out] = code
+	}
+
+	// Packages sharing a directory, like a package and its test variant, may
+	// report files generated by each other as orphans.
+	orphans := slices.DeleteFunc(g.orphans, func(out string) bool {
+		_, ok := g.files[out]
+		return ok
+	})
+	if g.opts.Strict {
+		errs = errors.Join(errs, warns)
+		warns = nil
+	}
+	if g.opts.Explain != "" && infos == nil && errs == nil {
+		errs = fmt.Errorf("no converter named %s", g.opts.Explain)
+	}
+	var graphData []byte
+	if g.opts.Graph != "" && errs == nil {
+		var err error
+		graphData, err = encodeGraphs(g.opts.Graph, graphs)
+		errs = err
 	}
 	if errs != nil {
 		// errs already contains comprehensive error messages. So we don't need
 		// to attach another error message.
-		return nil, reorderErrors(errs)
+		return Result{CacheHits: g.hits, CacheMisses: g.misses}, reorderErrors(errs)
 	}
 
-	return outs, nil
+	slices.Sort(orphans)
+	orphans = slices.Compact(orphans)
+	return Result{
+		Files:       g.files,
+		Packages:    g.pkgs,
+		Orphans:     orphans,
+		Warnings:    reorderErrors(warns),
+		Infos:       reorderErrors(infos),
+		Graph:       graphData,
+		CacheHits:   g.hits,
+		CacheMisses: g.misses,
+	}, nil
 }
 
-// load loads packages.
-func load(ctx context.Context, wd string, env []string, tags string, tests bool, patterns []string) ([]*packages.Package, error) {
-	cfg := &packages.Config{
-		Mode:       packages.NeedDeps | packages.NeedFiles | packages.NeedImports | packages.NeedName | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo,
-		Context:    ctx,
-		Dir:        wd,
-		Env:        env,
-		BuildFlags: []string{"-tags=convgen"},
-		Tests:      tests,
-	}
-	if tags != "" {
-		cfg.BuildFlags[0] += "," + tags
+// pkgResult is the result of building a package.
+type pkgResult struct {
+	files map[string][]byte
+	warns error
+	infos error
+	graph *graph
+	err   error
+}
+
+// build builds converters and generates code for the package. It is safe to
+// call concurrently for different packages.
+func (g *generator) build(pkg *packages.Package) pkgResult {
+	if len(pkg.Errors) != 0 {
+		return pkgResult{err: fmt.Errorf("pkg %q has errors", pkg.Name)}
 	}
 
-	// Load the packages based on the provided patterns.
-	pkgs, err := packages.Load(cfg, patterns...)
+	cg, err := New(pkg, BuildContext(g.opts.Env, g.opts.Tags))
 	if err != nil {
-		return nil, fmt.Errorf("failed to load packages: %w", err)
+		return pkgResult{err: err}
+	}
+
+	if err := cg.Build(); err != nil {
+		return pkgResult{err: err}
+	}
+
+	var infos error
+	if g.opts.Explain != "" {
+		infos = cg.Explain(g.opts.Explain)
+	}
+
+	var deps *graph
+	if g.opts.Graph != "" && len(cg.convs) != 0 {
+		deps = cg.Graph()
+	}
+
+	if len(pkg.GoFiles) == 0 {
+		// Nothing to generate
+		return pkgResult{warns: cg.Warnings(), infos: infos, graph: deps}
+	}
+
+	var files map[string][]byte
+	if g.opts.Split {
+		files, err = cg.GenerateFiles(g.opts.OutFile, g.opts.RoundTrip)
+		if err != nil {
+			return pkgResult{err: err}
+		}
+	} else if code := cg.Generate(); len(code) != 0 {
+		files = map[string][]byte{g.opts.OutFile: code}
+		if g.opts.RoundTrip {
+			if code := cg.GenerateRoundTripTests(); code != nil {
+				files[testFileName(g.opts.OutFile)] = code
+			}
+		}
 	}
-	if len(pkgs) == 0 {
-		return nil, fmt.Errorf("no packages found: %v", patterns)
+
+	if err := g.checkOverwrite(filepath.Dir(pkg.GoFiles[0]), files); err != nil {
+		return pkgResult{err: err}
 	}
 
-	// Check for errors in the loaded packages.
+	if g.opts.Manifest != "" && len(files) != 0 {
+		data, err := cg.Manifest(g.opts.Manifest)
+		if err != nil {
+			return pkgResult{err: err}
+		}
+		files[manifestFileName(g.opts.OutFile, g.opts.Manifest)] = data
+	}
+	return pkgResult{files: files, warns: cg.Warnings(), infos: infos, graph: deps}
+}
+
+// checkOverwrite fails if any Go file to generate in the directory already
+// exists but is not generated by Convgen, like a file of another generator
+// which has the same name in split mode.
+func (g *generator) checkOverwrite(dir string, files map[string][]byte) error {
 	var errs error
-	for _, pkg := range pkgs {
-		for _, err := range pkg.Errors {
-			if err.Pos == "" {
-				errs = errors.Join(errs, errors.New(err.Msg))
-				continue
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		path := filepath.Join(dir, name)
+		if filepath.Ext(name) != ".go" || isGeneratedFile(path) {
+			continue
+		}
+		if _, err := os.Stat(path); err == nil {
+			if rel, err := filepath.Rel(g.opts.WD, path); err == nil {
+				path = rel
 			}
+			errs = errors.Join(errs, fmt.Errorf("cannot overwrite %s: not generated by Convgen", path))
+		}
+	}
+	return errs
+}
 
-			path, rowcol, _ := strings.Cut(err.Pos, ":")
-			if rel, relErr := filepath.Rel(wd, path); relErr == nil {
-				err.Pos = rel + ":" + rowcol
+// add adds files generated for the package in the directory by their names.
+// It also finds orphaned files in the directory.
+func (g *generator) add(id, dir string, files map[string][]byte) {
+	outDir := dir
+	if rel, err := filepath.Rel(g.opts.WD, dir); err == nil {
+		outDir = rel
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		out := filepath.Join(outDir, name)
+		g.files[out] = files[name]
+		g.pkgs[id] = append(g.pkgs[id], out)
+	}
+
+	// If nothing is generated, the Convgen files may just not be loaded, like
+	// files for other GOOS or build tags. Files generated from them are not
+	// orphans unless the Convgen files are removed.
+	stale := len(files) != 0 || !hasConvgenFiles(dir)
+
+	if g.opts.Split {
+		for _, name := range generatedFiles(dir) {
+			if _, ok := files[name]; !ok && g.isSplitOrphan(dir, name, stale) {
+				g.orphans = append(g.orphans, filepath.Join(outDir, name))
 			}
-			errs = errors.Join(errs, err)
 		}
+		return
 	}
-	if errs != nil {
-		return nil, errs
+
+	if len(files) == 0 && stale && isGeneratedFile(filepath.Join(dir, g.opts.OutFile)) {
+		g.orphans = append(g.orphans, filepath.Join(outDir, g.opts.OutFile))
 	}
 
-	return pkgs, nil
+	// Round-trip tests of removed converters would break the tests.
+	testFile := testFileName(g.opts.OutFile)
+	if _, ok := files[testFile]; !ok && g.opts.RoundTrip && stale && isGeneratedFile(filepath.Join(dir, testFile)) {
+		g.orphans = append(g.orphans, filepath.Join(outDir, testFile))
+	}
+}
+
+// isSplitOrphan reports whether the generated file in split mode, which is not
+// generated by this run, is an orphan. A file generated from a Convgen file is
+// an orphan only if the Convgen file is removed, because the Convgen file may
+// just not be loaded, like test files without -t or files for other build
+// tags. The files of implicit converters are orphans only if stale.
+func (g *generator) isSplitOrphan(dir, name string, stale bool) bool {
+	switch name {
+	case g.opts.OutFile:
+		return stale
+	case testFileName(g.opts.OutFile):
+		// Implicit converters of test files and round-trip tests
+		return stale && (g.opts.Tests || g.opts.RoundTrip)
+	}
+
+	src, ok := srcFileName(name)
+	if !ok {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(dir, src))
+	return errors.Is(err, fs.ErrNotExist)
+}
+
+// isGeneratedFile reports whether the file at path exists and is generated by
+// Convgen.
+func isGeneratedFile(path string) bool {
+	code, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return IsGenerated(code)
+}
+
+// hasConvgenFiles reports whether the directory has any Go file with a
+// "//go:build convgen" constraint, whether or not it is loaded. Files generated
+// by Convgen are not counted.
+func hasConvgenFiles(dir string) bool {
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+
+	fset := token.NewFileSet()
+	for _, path := range paths {
+		if isGeneratedFile(path) {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.PackageClauseOnly|parser.ParseComments)
+		if err == nil && parse.HasGoBuildConvgen(file) {
+			return true
+		}
+	}
+	return false
+}
+
+// generatedFiles returns the names of the Go files generated by Convgen in
+// the directory.
+func generatedFiles(dir string) []string {
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+
+	var names []string
+	for _, path := range paths {
+		if isGeneratedFile(path) {
+			names = append(names, filepath.Base(path))
+		}
+	}
+	return names
 }
 
 func reorderErrors(errs error) error {
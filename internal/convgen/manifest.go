@@ -0,0 +1,125 @@
+package convgeninternal
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/sublee/convgen/internal/codefmt"
+	"github.com/sublee/convgen/internal/convgen/assign"
+)
+
+// manifest is a machine-readable description of the converters in a package.
+// Reviewers and external tools can see what Convgen matched without reading
+// the generated code.
+type manifest struct {
+	Package    string              `json:"package" yaml:"package"`
+	Converters []manifestConverter `json:"converters" yaml:"converters"`
+}
+
+// manifestConverter describes an explicit or implicit converter.
+type manifestConverter struct {
+	Name     string         `json:"name" yaml:"name"`
+	X        string         `json:"x" yaml:"x"`
+	Y        string         `json:"y" yaml:"y"`
+	Kind     string         `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Implicit bool           `json:"implicit,omitempty" yaml:"implicit,omitempty"`
+	Pos      string         `json:"pos,omitempty" yaml:"pos,omitempty"`
+	Via      string         `json:"via,omitempty" yaml:"via,omitempty"`
+	Pairs    []manifestPair `json:"pairs,omitempty" yaml:"pairs,omitempty"`
+	Funcs    []string       `json:"funcs,omitempty" yaml:"funcs,omitempty"`
+}
+
+// manifestPair describes a pair of fields, union implementations, or enum
+// members. X or Y is omitted if it is missing.
+type manifestPair struct {
+	X       string `json:"x,omitempty" yaml:"x,omitempty"`
+	Y       string `json:"y,omitempty" yaml:"y,omitempty"`
+	Skipped bool   `json:"skipped,omitempty" yaml:"skipped,omitempty"`
+	Reason  string `json:"reason,omitempty" yaml:"reason,omitempty"`
+	At      string `json:"at,omitempty" yaml:"at,omitempty"`
+	Via     string `json:"via,omitempty" yaml:"via,omitempty"`
+}
+
+// manifestFileName returns the name of the manifest file in the format next to
+// the output file, like "convgen_gen_manifest.json" for "convgen_gen.go".
+func manifestFileName(outFile, format string) string {
+	return strings.TrimSuffix(outFile, ".go") + "_manifest." + format
+}
+
+// Manifest returns the mapping manifest of the explicit and implicit converters
+// encoded in the format, "json" or "yaml". Positions are relative to the
+// directory of the package. It must be called after [Convgen.Build] succeeds.
+func (cg *Convgen) Manifest(format string) ([]byte, error) {
+	pkg := cg.p.Pkg()
+	m := manifest{Package: pkg.PkgPath, Converters: []manifestConverter{}}
+
+	for _, conv := range cg.sortedConvs() {
+		c := cg.manifestConverter(assign.Describe(conv))
+		c.Name = cg.injs[conv.Pos()].Name()
+		c.Pos = cg.formatPos(conv.Pos())
+		m.Converters = append(m.Converters, c)
+	}
+	for _, mod := range cg.sortedMods() {
+		for _, conv := range cg.subconvs[mod] {
+			m.Converters = append(m.Converters, cg.manifestConverter(assign.Describe(conv)))
+		}
+	}
+
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case "yaml":
+		return yaml.Marshal(m)
+	}
+	return nil, fmt.Errorf("unknown manifest format %q", format)
+}
+
+// manifestConverter converts a mapping into its manifest entry.
+func (cg *Convgen) manifestConverter(mapping assign.Mapping) manifestConverter {
+	c := manifestConverter{
+		Name:     mapping.Name,
+		X:        codefmt.FormatType(cg.p, mapping.X),
+		Y:        codefmt.FormatType(cg.p, mapping.Y),
+		Kind:     mapping.Kind,
+		Implicit: mapping.Implicit,
+		Via:      mapping.Via,
+	}
+	for _, p := range mapping.Pairs {
+		c.Pairs = append(c.Pairs, manifestPair{
+			X:       p.X,
+			Y:       p.Y,
+			Skipped: p.Skipped,
+			Reason:  p.Reason,
+			At:      cg.formatPos(p.At),
+			Via:     p.Via,
+		})
+	}
+	for _, obj := range mapping.Funcs {
+		if fn, ok := obj.(*types.Func); ok {
+			c.Funcs = append(c.Funcs, fn.FullName())
+		} else {
+			c.Funcs = append(c.Funcs, obj.Pkg().Path()+"."+obj.Name())
+		}
+	}
+	return c
+}
+
+// formatPos formats the position as "file.go:line:col" with the base name of
+// the file. It returns an empty string for token.NoPos.
+func (cg *Convgen) formatPos(pos token.Pos) string {
+	if !pos.IsValid() {
+		return ""
+	}
+	p := cg.p.Pkg().Fset.Position(pos)
+	return fmt.Sprintf("%s:%d:%d", filepath.Base(p.Filename), p.Line, p.Column)
+}
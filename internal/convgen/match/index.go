@@ -43,20 +43,27 @@ type index struct {
 	All   []entry
 	ByKey map[string][]entry
 	ByPos map[token.Pos]entry
+
+	// Keys holds the keys of each entry before and after each renamer which
+	// changed it. The last one is the key of the entry.
+	Keys map[token.Pos][]string
 }
 
-// build builds an index of entries by their paths and keys.
-func (i *indexer[T]) build(renamers []renameFunc, commonFinders []findCommonFunc) index {
+// build builds an index of entries by their paths and keys. renamed is called
+// with the index of each renamer which changed at least one key. It may be nil.
+func (i *indexer[T]) build(renamers []renameFunc, commonFinders []findCommonFunc, renamed func(int)) index {
 	idx := index{
 		All:   make([]entry, 0, i.m.Size()),
 		ByKey: make(map[string][]entry, i.m.Size()/2),
 		ByPos: make(map[token.Pos]entry, i.m.Size()),
+		Keys:  make(map[token.Pos][]string, i.m.Size()),
 	}
 
 	it := i.m.Iterator()
 	for it.Next() {
 		e := it.Value().(entry)
 		idx.All = append(idx.All, e)
+		idx.Keys[e.Pos()] = []string{e.key}
 	}
 
 	// rename keys
@@ -71,8 +78,18 @@ func (i *indexer[T]) build(renamers []renameFunc, commonFinders []findCommonFunc
 			common = find(keys)
 		}
 
+		changed := false
 		for j := range idx.All {
-			idx.All[j].key = rename(idx.All[j].key, common)
+			key := rename(idx.All[j].key, common)
+			if key != idx.All[j].key {
+				changed = true
+				pos := idx.All[j].Pos()
+				idx.Keys[pos] = append(idx.Keys[pos], key)
+			}
+			idx.All[j].key = key
+		}
+		if changed && renamed != nil {
+			renamed(i)
 		}
 	}
 
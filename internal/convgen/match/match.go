@@ -1,6 +1,7 @@
 package match
 
 import (
+	"fmt"
 	"go/token"
 	"strings"
 
@@ -9,6 +10,7 @@ import (
 
 	"github.com/sublee/convgen/internal/codefmt"
 	"github.com/sublee/convgen/internal/convgen/parse"
+	"github.com/sublee/convgen/internal/typeinfo"
 )
 
 type (
@@ -26,6 +28,7 @@ type (
 // the matches satisfy the expectations (missing or specific matches).
 type Matcher[T object] struct {
 	x, y object
+	inj  parse.Injector
 	pkg  *packages.Package
 	pos  token.Pos
 
@@ -37,6 +40,7 @@ type Matcher[T object] struct {
 	skippedAt *linkedhashmap.Map                  // [posX, posY] -> where convgen.MatchSkip is called in order
 
 	renamersX, renamersY           []renameFunc
+	renamersAtX, renamersAtY       []token.Pos
 	commonFindersX, commonFindersY []findCommonFunc
 }
 
@@ -47,6 +51,7 @@ func NewMatcher[T object](inj parse.Injector, cfg parse.Config, x, y object) *Ma
 	m := &Matcher[T]{
 		x:   x,
 		y:   y,
+		inj: inj,
 		pkg: inj.Pkg(),
 		pos: inj.Pos(),
 
@@ -59,6 +64,8 @@ func NewMatcher[T object](inj parse.Injector, cfg parse.Config, x, y object) *Ma
 
 		renamersX:      cfg.RenamersX,
 		renamersY:      cfg.RenamersY,
+		renamersAtX:    cfg.RenamersAtX,
+		renamersAtY:    cfg.RenamersAtY,
 		commonFindersX: cfg.CommonFindersX,
 		commonFindersY: cfg.CommonFindersY,
 	}
@@ -119,22 +126,133 @@ func (m *Matcher[T]) Match() ([]Match[T], error) {
 			b.WriteString("\t")
 			b.WriteString(line)
 		}
-		return nil, codefmt.Errorf(m, m, "invalid match between %s and %s\n%s", m.x.DebugName(), m.y.DebugName(), b.String())
+		err := codefmt.Errorf(m, m, "invalid match between %s and %s\n%s", m.x.DebugName(), m.y.DebugName(), b.String())
+		err = codefmt.WithMatchRows(err, vis.Rows()...)
+		return nil, codefmt.WithFixes(err, m.fixes(vis)...)
 	}
 
 	return matches, nil
 }
 
+// fixes returns suggested fixes for missing entries. A suggested pair is fixed
+// by convgen.Match and an unpaired missing entry is fixed by convgen.MatchSkip.
+// Fixes are available only for explicit converters because options cannot be
+// added to implicit subconverters.
+func (m *Matcher[T]) fixes(vis *visualizer) []codefmt.Fix {
+	if !m.isExplicit() {
+		return nil
+	}
+
+	var fixes []codefmt.Fix
+	paired := make(map[entry]bool)
+	for _, s := range vis.suggestions {
+		paired[s.x] = true
+		paired[s.y] = true
+
+		msg := fmt.Sprintf("Match %s and %s", pathExpr(s.x), pathExpr(s.y))
+		if fix, ok := m.inj.FixAddOption(msg, "Match", pathExpr(s.x), pathExpr(s.y)); ok {
+			fixes = append(fixes, fix)
+		}
+	}
+
+	xs, ys := vis.Missing()
+	for _, x := range xs {
+		if paired[x] {
+			continue
+		}
+		msg := fmt.Sprintf("Skip missing %s", pathExpr(x))
+		if fix, ok := m.inj.FixAddOption(msg, "MatchSkip", pathExpr(x), "nil"); ok {
+			fixes = append(fixes, fix)
+		}
+	}
+	for _, y := range ys {
+		if paired[y] {
+			continue
+		}
+		msg := fmt.Sprintf("Skip missing %s", pathExpr(y))
+		if fix, ok := m.inj.FixAddOption(msg, "MatchSkip", "nil", pathExpr(y)); ok {
+			fixes = append(fixes, fix)
+		}
+	}
+	return fixes
+}
+
+// isExplicit reports whether X and Y are the input and output of the explicit
+// converter rather than an implicit subconverter.
+func (m *Matcher[T]) isExplicit() bool {
+	if !m.inj.Explicit() || m.inj.Func == nil {
+		return false
+	}
+
+	type typer interface{ Type() typeinfo.Type }
+	x, okX := m.x.(typer)
+	y, okY := m.y.(typer)
+	if !okX || !okY {
+		return false
+	}
+	return x.Type().Identical(m.inj.X()) && y.Type().Identical(m.inj.Y())
+}
+
 func (m *Matcher[T]) Visualize() string {
 	_, vis := m.matchVisualize()
 	return vis.String()
 }
 
+// Row is a row of the match table. X or Y is the zero value if it is missing.
+type Row[T any] struct {
+	X, Y       T
+	HasX, HasY bool
+
+	OK, Skipped bool
+
+	// Reason explains the row, like "forced" or "skipped missing". At is the
+	// position where the reason comes from, such as a convgen.Match call. It
+	// is token.NoPos if the reason does not come from a position.
+	Reason string
+	At     token.Pos
+}
+
+// Table returns the rows of the match table in the same order as
+// [Matcher.Visualize].
+func (m *Matcher[T]) Table() []Row[T] {
+	_, vis := m.matchVisualize()
+
+	var rows []Row[T]
+	for _, pair := range vis.sortedPairs() {
+		x, y := pair[0], pair[1]
+		v := vis.matches[pair]
+
+		row := Row[T]{OK: v.ok, Skipped: v.skipped, Reason: v.reason}
+		if v.at != noAt {
+			row.At = v.at
+		}
+		if x.IsValid() {
+			row.X, row.HasX = x.object.(T), true
+		}
+		if y.IsValid() {
+			row.Y, row.HasY = y.object.(T), true
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// Explain returns the match table of successful matching in detail. Each entry
+// shows its original name and its key after each effective renamer. how
+// describes the way to assign X to Y of each match.
+func (m *Matcher[T]) Explain(how func(x, y T) string) string {
+	_, vis := m.matchVisualize()
+	return vis.Explain(func(x, y entry) string {
+		return how(x.object.(T), y.object.(T))
+	})
+}
+
 func (m *Matcher[T]) matchVisualize() ([]Match[T], *visualizer) {
-	xs := m.xs.build(m.renamersX, m.commonFindersX)
-	ys := m.ys.build(m.renamersY, m.commonFindersY)
+	xs := m.xs.build(m.renamersX, m.commonFindersX, m.useRenamer(m.renamersAtX))
+	ys := m.ys.build(m.renamersY, m.commonFindersY, m.useRenamer(m.renamersAtY))
 	ln := newLinks()
-	vis := newVisualizer()
+	vis := newVisualizer(func(pos token.Pos) string { return codefmt.Sprintf(m, "%b", pos) })
+	vis.keysX, vis.keysY = xs.Keys, ys.Keys
 
 	// Apply matching and validation rules (order matters)
 	m.ruleMatch(xs, ys, ln, vis)
@@ -142,6 +260,7 @@ func (m *Matcher[T]) matchVisualize() ([]Match[T], *visualizer) {
 	m.ruleMissing(xs, ys, ln, vis)
 	m.ruleSkip(xs, ys, ln, vis)
 	m.ruleAmbiguous(xs, ys, ln, vis)
+	m.ruleSuggest(vis)
 
 	matches := make([]Match[T], 0)
 	for _, x := range xs.All {
@@ -152,6 +271,16 @@ func (m *Matcher[T]) matchVisualize() ([]Match[T], *visualizer) {
 	return matches, vis
 }
 
+// useRenamer returns a callback which records the renamer at the given index as
+// effective.
+func (m *Matcher[T]) useRenamer(at []token.Pos) func(int) {
+	return func(i int) {
+		if i < len(at) {
+			m.inj.Usage().Use(at[i])
+		}
+	}
+}
+
 // ruleMatch links X and Y by key when neither side has a forced match.
 func (m *Matcher[T]) ruleMatch(xs, ys index, ln *links, vis *visualizer) {
 	for _, x := range xs.All {
@@ -165,7 +294,7 @@ func (m *Matcher[T]) ruleMatch(xs, ys index, ln *links, vis *visualizer) {
 
 			// No forced match, so link by key
 			ln.Link(x, y)
-			vis.Match(x, y, "")
+			vis.Match(x, y, "", noAt)
 		}
 	}
 }
@@ -179,8 +308,7 @@ func (m *Matcher[T]) ruleForced(xs, ys index, ln *links, vis *visualizer) {
 		ln.Link(x, y)
 
 		pos := m.forcedAt[[2]token.Pos{posX, posY}]
-		reason := codefmt.Sprintf(m, "forced at %b", pos)
-		vis.Match(x, y, reason)
+		vis.Match(x, y, "forced", pos)
 	}
 }
 
@@ -192,22 +320,20 @@ func (m *Matcher[T]) ruleMissing(xs, ys index, ln *links, vis *visualizer) {
 	for _, x := range xs.All {
 		if len(ln.FromX(x)) == 0 {
 			if pos, ok := m.skippedAt.Get([2]token.Pos{x.Pos(), token.NoPos}); ok {
-				reason := codefmt.Sprintf(m, "skipped missing at %b", pos)
-				vis.Skip(x, missing, reason)
+				vis.Skip(x, missing, "skipped missing", pos.(token.Pos))
 			} else {
-				vis.MatchFail(x, missing, "missing")
+				vis.MatchFail(x, missing, "missing", noAt)
 			}
 		}
 	}
 	for _, y := range ys.All {
 		if len(ln.FromY(y)) == 0 {
 			if pos, ok := m.skippedAt.Get([2]token.Pos{token.NoPos, y.Pos()}); ok {
-				reason := codefmt.Sprintf(m, "skipped missing at %b", pos)
-				vis.Skip(missing, y, reason)
+				vis.Skip(missing, y, "skipped missing", pos.(token.Pos))
 			} else if y.Pos() == m.defaultY {
-				vis.Match(missing, y, "missing allowed as default")
+				vis.Match(missing, y, "missing allowed as default", noAt)
 			} else {
-				vis.MatchFail(missing, y, "missing")
+				vis.MatchFail(missing, y, "missing", noAt)
 			}
 		}
 	}
@@ -218,14 +344,14 @@ func (m *Matcher[T]) ruleMissing(xs, ys index, ln *links, vis *visualizer) {
 func (m *Matcher[T]) ruleSkip(xs, ys index, ln *links, vis *visualizer) {
 	for _, pair := range m.skippedAt.Keys() {
 		posX, posY := pair.([2]token.Pos)[0], pair.([2]token.Pos)[1]
-		pos, _ := m.skippedAt.Get(pair)
+		at, _ := m.skippedAt.Get(pair)
+		pos := at.(token.Pos)
 
 		x := xs.ByPos[posX]
 		y := ys.ByPos[posY]
 
 		if !ln.Linked(x, y) {
-			reason := codefmt.Sprintf(m, "ineffective skip at %b", pos)
-			vis.SkipFail(x, y, reason)
+			vis.SkipFail(x, y, "ineffective skip", pos)
 			continue
 		}
 
@@ -237,8 +363,7 @@ func (m *Matcher[T]) ruleSkip(xs, ys index, ln *links, vis *visualizer) {
 		// Unlink skipped matches
 		ln.Unlink(x, y)
 
-		reason := codefmt.Sprintf(m, "skipped match at %b", pos)
-		vis.Skip(x, y, reason)
+		vis.Skip(x, y, "skipped match", pos)
 	}
 }
 
@@ -251,8 +376,17 @@ func (m *Matcher[T]) ruleAmbiguous(xs, ys index, ln *links, vis *visualizer) {
 				// If single Y is linked from multiple Xs, it's ambiguous
 				// because there is information loss. However, single X can link
 				// to multiple Ys.
-				vis.MatchFail(x, y, "ambiguous")
+				vis.MatchFail(x, y, "ambiguous", noAt)
 			}
 		}
 	}
 }
+
+// ruleSuggest suggests likely intended pairs between missing Xs and missing Ys
+// to help fix typos and naming differences.
+func (m *Matcher[T]) ruleSuggest(vis *visualizer) {
+	xs, ys := vis.Missing()
+	for _, s := range suggest(xs, ys) {
+		vis.Suggest(s)
+	}
+}
@@ -226,3 +226,65 @@ func TestMatchErrorIndent(t *testing.T) {
 	FAIL: x -> ? // missing
 	FAIL: ? -> y // missing`)
 }
+
+func TestSuggest(t *testing.T) {
+	m := match.NewMatcher[Obj](anInj, parse.Config{}, dummy, dummy)
+	m.AddX(Obj{1, "User.Adress"}, "Adress")
+	m.AddY(Obj{2, "Person.Address"}, "Address")
+	m.AddX(Obj{3, "User.EMail"}, "EMail")
+	m.AddY(Obj{4, "Person.Email"}, "Email")
+	m.AddX(Obj{5, "User.StatusCode"}, "StatusCode")
+	m.AddY(Obj{6, "Person.StateCode"}, "StateCode")
+	m.AddX(Obj{7, "User.Zip"}, "Zip")
+	m.AddY(Obj{8, "Person.Phone"}, "Phone")
+	m.AddX(Obj{9, "User.UserID"}, "UserID")
+	m.AddY(Obj{10, "Person.OrderID"}, "OrderID")
+	m.AddX(Obj{11, "User.ID"}, "ID")
+	m.AddY(Obj{12, "Person.OwnerID"}, "OwnerID")
+
+	v := m.Visualize()
+	assert.Contains(t, ss(v), ss(`
+hint: did you mean Adress -> Address? try convgen.Match(User.Adress, Person.Address)
+hint: did you mean EMail -> Email? try convgen.Match(User.EMail, Person.Email) or convgen.RenameToLower(true, true)
+hint: did you mean StatusCode -> StateCode? try convgen.Match(User.StatusCode, Person.StateCode) or convgen.RenameTrimPrefix("Status", "State")
+`), v)
+	assert.NotContains(t, v, "Zip ->")
+	assert.NotContains(t, v, "UserID ->")
+	assert.NotContains(t, v, "ID ->")
+}
+
+func TestExplain(t *testing.T) {
+	toLower := func(s, _ string) string { return strings.ToLower(s) }
+	trimID := func(s, _ string) string { return strings.TrimSuffix(s, "_id") }
+	cfg := parse.Config{
+		RenamersX:      []func(string, string) string{toLower, trimID},
+		CommonFindersX: []func([]string) string{nil, nil},
+		RenamersY:      []func(string, string) string{toLower},
+		CommonFindersY: []func([]string) string{nil},
+	}
+
+	m := match.NewMatcher[Obj](anInj, cfg, dummy, dummy)
+	m.AddX(Obj{1, "User.Group_ID"}, "Group_ID")
+	m.AddY(Obj{2, "Person.Group"}, "Group")
+	m.AddX(Obj{3, "User.name"}, "name")
+	m.AddY(Obj{4, "Person.name"}, "name")
+
+	v := m.Explain(func(x, y Obj) string { return x.cn + " to " + y.cn })
+	assert.Equal(t, ss(`
+ok: Group_ID [group_id > group] -> Group [group] // User.Group_ID to Person.Group
+ok: name                        -> name          // User.name to Person.name
+`), ss(v), v)
+}
+
+func TestTable(t *testing.T) {
+	m := match.NewMatcher[Obj](anInj, parse.Config{}, dummy, dummy)
+	m.AddX(Obj{1, "fruit.apple"}, "A")
+	m.AddY(Obj{2, "person.alice"}, "A")
+	m.AddX(Obj{3, "fruit.banana"}, "B")
+	m.Skip(3, token.NoPos, 100)
+
+	rows := m.Table()
+	require.Len(t, rows, 2)
+	assert.Equal(t, match.Row[Obj]{X: Obj{1, "fruit.apple"}, Y: Obj{2, "person.alice"}, HasX: true, HasY: true, OK: true}, rows[0])
+	assert.Equal(t, match.Row[Obj]{X: Obj{3, "fruit.banana"}, HasX: true, OK: true, Skipped: true, Reason: "skipped missing", At: 100}, rows[1])
+}
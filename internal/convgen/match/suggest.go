@@ -0,0 +1,154 @@
+package match
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sublee/convgen/internal/lcs"
+)
+
+// pathExprer is optionally implemented by objects to render themselves as a
+// path expression for convgen.Match, like "User{}.Name". If an object does not
+// implement it, its crumb name is used instead.
+type pathExprer interface {
+	PathExpr() string
+}
+
+// pathExpr returns the path expression of the given entry.
+func pathExpr(e entry) string {
+	if pe, ok := e.object.(pathExprer); ok {
+		return pe.PathExpr()
+	}
+	return e.CrumbName()
+}
+
+// suggestion is a likely intended pair between a missing X and a missing Y.
+type suggestion struct {
+	x, y entry
+
+	// match is a convgen.Match option that forces the pair.
+	match string
+
+	// rename is a renaming option that makes their keys equal. It is empty if
+	// no simple renaming is found.
+	rename string
+
+	// distance is the edit distance between the keys in lower case.
+	distance int
+}
+
+// String returns a hint line for the suggestion.
+func (s suggestion) String() string {
+	hint := fmt.Sprintf("did you mean %s -> %s? try %s", s.x, s.y, s.match)
+	if s.rename != "" {
+		hint += " or " + s.rename
+	}
+	return hint
+}
+
+// suggest pairs up missing Xs and missing Ys which look similar. Each X and Y
+// appears in at most one suggestion.
+func suggest(xs, ys []entry) []suggestion {
+	var candidates []suggestion
+	for _, x := range xs {
+		for _, y := range ys {
+			s, ok := suggestPair(x, y)
+			if ok {
+				candidates = append(candidates, s)
+			}
+		}
+	}
+
+	// Prefer pairs which can be fixed by renaming and then closer pairs.
+	slices.SortStableFunc(candidates, func(a, b suggestion) int {
+		if (a.rename != "") != (b.rename != "") {
+			if a.rename != "" {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(a.distance, b.distance)
+	})
+
+	var suggestions []suggestion
+	usedX := make(map[entry]bool)
+	usedY := make(map[entry]bool)
+	for _, s := range candidates {
+		if usedX[s.x] || usedY[s.y] {
+			continue
+		}
+		usedX[s.x] = true
+		usedY[s.y] = true
+		suggestions = append(suggestions, s)
+	}
+
+	slices.SortFunc(suggestions, func(a, b suggestion) int {
+		return cmp.Compare(a.x.Pos(), b.x.Pos())
+	})
+	return suggestions
+}
+
+// suggestPair reports whether x and y look similar enough to suggest.
+func suggestPair(x, y entry) (suggestion, bool) {
+	s := suggestion{
+		x:        x,
+		y:        y,
+		match:    fmt.Sprintf("convgen.Match(%s, %s)", pathExpr(x), pathExpr(y)),
+		rename:   suggestRename(x.key, y.key),
+		distance: lcs.Distance(strings.ToLower(x.key), strings.ToLower(y.key)),
+	}
+
+	// Similar if at most a third of the longer key should be edited. Sharing
+	// a word is not enough, like UserID and OrderID.
+	if s.distance*3 > max(len(x.key), len(y.key)) {
+		return suggestion{}, false
+	}
+	return s, true
+}
+
+// suggestRename finds a renaming option which makes keyX and keyY equal. It
+// returns an empty string if there is no such simple option.
+func suggestRename(keyX, keyY string) string {
+	if strings.EqualFold(keyX, keyY) {
+		return "convgen.RenameToLower(true, true)"
+	}
+
+	wordsX := lcs.SplitWords(keyX)
+	wordsY := lcs.SplitWords(keyY)
+
+	// Count common leading and trailing words.
+	n := min(len(wordsX), len(wordsY))
+	head := 0
+	for head < n && wordsX[head] == wordsY[head] {
+		head++
+	}
+	tail := 0
+	for tail < n && wordsX[len(wordsX)-1-tail] == wordsY[len(wordsY)-1-tail] {
+		tail++
+	}
+
+	// An empty prefix or suffix would trim the other side only, which renames
+	// fields beyond the pair, like ID and UserID.
+
+	// e.g., FooName -> BarName: RenameTrimPrefix("Foo", "Bar")
+	if tail != 0 && head == 0 {
+		prefixX := strings.Join(wordsX[:len(wordsX)-tail], "")
+		prefixY := strings.Join(wordsY[:len(wordsY)-tail], "")
+		if prefixX != "" && prefixY != "" {
+			return fmt.Sprintf("convgen.RenameTrimPrefix(%q, %q)", prefixX, prefixY)
+		}
+	}
+
+	// e.g., NameFoo -> NameBar: RenameTrimSuffix("Foo", "Bar")
+	if head != 0 && tail == 0 {
+		suffixX := strings.Join(wordsX[head:], "")
+		suffixY := strings.Join(wordsY[head:], "")
+		if suffixX != "" && suffixY != "" {
+			return fmt.Sprintf("convgen.RenameTrimSuffix(%q, %q)", suffixX, suffixY)
+		}
+	}
+
+	return ""
+}
@@ -2,12 +2,15 @@ package match
 
 import (
 	"cmp"
+	"fmt"
 	"go/token"
 	"io"
 	"maps"
 	"slices"
 	"strings"
 	"text/tabwriter"
+
+	"github.com/sublee/convgen/internal/codefmt"
 )
 
 // visualizer helps visualize the matching results and validation errors. It
@@ -17,13 +20,24 @@ import (
 //	FAIL: B -> ?   // missing
 //	ok:   C -> Cat // forced at main.go:10:5
 //	ok:   D .. ?   // skipped missing at main.go:11:5
+//	FAIL: Eml -> ? // missing
+//	FAIL: ? -> Email // missing
+//	hint: did you mean Eml -> Email? try convgen.Match(X{}.Eml, Y{}.Email)
 type visualizer struct {
-	matches map[[2]entry]validity
+	matches     map[[2]entry]validity
+	suggestions []suggestion
+
+	// keysX and keysY are the keys of entries after each renamer. See
+	// [index.Keys].
+	keysX, keysY map[token.Pos][]string
+
+	// formatPos formats the position where a reason comes from.
+	formatPos func(token.Pos) string
 }
 
 // newVisualizer creates a new visualizer.
-func newVisualizer() *visualizer {
-	return &visualizer{matches: make(map[[2]entry]validity)}
+func newVisualizer(formatPos func(token.Pos) string) *visualizer {
+	return &visualizer{matches: make(map[[2]entry]validity), formatPos: formatPos}
 }
 
 // validity represents whether a match is valid, skipped, and the reason.
@@ -31,8 +45,12 @@ type validity struct {
 	ok      bool
 	skipped bool
 	reason  string
+	at      token.Pos // where the reason comes from, or noAt
 }
 
+// noAt is the position of a reason which does not come from a position.
+const noAt token.Pos = -1
+
 // IsValid reports whether all matches are valid.
 func (vis visualizer) IsValid() bool {
 	for _, v := range vis.matches {
@@ -55,49 +73,145 @@ func (vis *visualizer) put(x, y entry, val validity) {
 	}
 }
 
-// Match records a valid match.
-func (vis *visualizer) Match(x, y entry, reason string) {
-	vis.put(x, y, validity{ok: true, skipped: false, reason: reason})
+// Match records a valid match. at is the position where the reason comes
+// from, such as a convgen.Match call, or noAt.
+func (vis *visualizer) Match(x, y entry, reason string, at token.Pos) {
+	vis.put(x, y, validity{ok: true, skipped: false, reason: reason, at: at})
 }
 
 // Skip records a skipped match.
-func (vis *visualizer) Skip(x, y entry, reason string) {
-	vis.put(x, y, validity{ok: true, skipped: true, reason: reason})
+func (vis *visualizer) Skip(x, y entry, reason string, at token.Pos) {
+	vis.put(x, y, validity{ok: true, skipped: true, reason: reason, at: at})
 }
 
-func (vis *visualizer) MatchFail(x, y entry, reason string) {
-	vis.put(x, y, validity{ok: false, skipped: false, reason: reason})
+func (vis *visualizer) MatchFail(x, y entry, reason string, at token.Pos) {
+	vis.put(x, y, validity{ok: false, skipped: false, reason: reason, at: at})
 }
 
 // Dots records a skipped match.
-func (vis *visualizer) SkipFail(x, y entry, reason string) {
-	vis.put(x, y, validity{ok: false, skipped: true, reason: reason})
+func (vis *visualizer) SkipFail(x, y entry, reason string, at token.Pos) {
+	vis.put(x, y, validity{ok: false, skipped: true, reason: reason, at: at})
 }
 
-// String returns the string representation of the visualizer.
-func (vis visualizer) String() string {
-	var b strings.Builder
-	tw := tabwriter.NewWriter(&b, 1, 1, 1, ' ', 0)
+// reasonOf returns the reason of the validity with the position where it
+// comes from, like "forced at main.go:10:5".
+func (vis visualizer) reasonOf(v validity) string {
+	if v.at == noAt {
+		return v.reason
+	}
+	return v.reason + " at " + vis.formatPos(v.at)
+}
 
-	pairs := slices.Collect(maps.Keys(vis.matches))
-	slices.SortFunc(pairs, func(a, b [2]entry) int {
-		if cmp := cmpValidWinsInvalid(a[0].Pos(), b[0].Pos()); cmp != 0 {
-			return cmp
+// Suggest records a suggestion for missing entries.
+func (vis *visualizer) Suggest(s suggestion) {
+	vis.suggestions = append(vis.suggestions, s)
+}
+
+// Missing returns Xs and Ys which failed to match because of their missing
+// counterparts. They are sorted by their positions.
+func (vis visualizer) Missing() (xs, ys []entry) {
+	for pair, v := range vis.matches {
+		if v.ok || v.skipped {
+			continue
 		}
-		if cmp := cmpAboveWinsBelow(a[0].Pos(), b[0].Pos()); cmp != 0 {
-			return cmp
+		x, y := pair[0], pair[1]
+		switch {
+		case x.IsValid() && !y.IsValid():
+			xs = append(xs, x)
+		case !x.IsValid() && y.IsValid():
+			ys = append(ys, y)
 		}
-		if cmp := cmpValidWinsInvalid(a[1].Pos(), b[1].Pos()); cmp != 0 {
-			return cmp
+	}
+
+	byPos := func(a, b entry) int { return cmp.Compare(a.Pos(), b.Pos()) }
+	slices.SortFunc(xs, byPos)
+	slices.SortFunc(ys, byPos)
+	return xs, ys
+}
+
+// Rows returns the match results as rows of a table in the same order as
+// [visualizer.String].
+func (vis visualizer) Rows() []codefmt.MatchRow {
+	var rows []codefmt.MatchRow
+	for _, pair := range vis.sortedPairs() {
+		v := vis.matches[pair]
+		rows = append(rows, codefmt.MatchRow{
+			OK:      v.ok,
+			Skipped: v.skipped,
+			X:       pair[0].String(),
+			Y:       pair[1].String(),
+			Reason:  vis.reasonOf(v),
+		})
+	}
+	return rows
+}
+
+// String returns the string representation of the visualizer.
+func (vis visualizer) String() string {
+	b := vis.table(entry.String, entry.String, func(x, y entry, v validity) string {
+		return vis.reasonOf(v)
+	})
+
+	// Hints are written after the table not to affect the alignment.
+	for _, s := range vis.suggestions {
+		b.WriteString("\nhint: ")
+		b.WriteString(s.String())
+	}
+	return b.String()
+}
+
+// Explain returns the string representation of the visualizer in detail for
+// successful matching. Each entry shows its original name and its keys after
+// renamers, like "UserID [userid > user_id]". how describes the way to assign
+// X to Y of each valid match.
+//
+//	ok: Name [name]     -> Name [name]     // assignment
+//	ok: UserID [userid] -> UserId [userid] // function strconv.Itoa
+func (vis visualizer) Explain(how func(x, y entry) string) string {
+	nameX := func(e entry) string { return explainEntry(e, vis.keysX[e.Pos()]) }
+	nameY := func(e entry) string { return explainEntry(e, vis.keysY[e.Pos()]) }
+	b := vis.table(nameX, nameY, func(x, y entry, v validity) string {
+		reason := vis.reasonOf(v)
+		if !v.ok || v.skipped || !x.IsValid() || !y.IsValid() {
+			return reason
 		}
-		if cmp := cmpAboveWinsBelow(a[1].Pos(), b[1].Pos()); cmp != 0 {
-			return cmp
+		if reason == "" {
+			return how(x, y)
 		}
-		return 0
+		return reason + "; " + how(x, y)
 	})
+	return b.String()
+}
+
+// explainEntry formats the entry with its keys after renamers.
+func explainEntry(e entry, keys []string) string {
+	if !e.IsValid() {
+		return "?"
+	}
+
+	name := e.CrumbName()
+	if i := strings.Index(name, "."); i != -1 {
+		// Discard the leading path if it exists.
+		name = name[i+1:]
+	}
+
+	if len(keys) != 0 && keys[0] == name {
+		keys = keys[1:]
+	}
+	if len(keys) == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s [%s]", name, strings.Join(keys, " > "))
+}
+
+// table renders the matches as a table. nameX and nameY format the entries
+// and note formats the trailing comment of each row.
+func (vis visualizer) table(nameX, nameY func(entry) string, note func(x, y entry, v validity) string) *strings.Builder {
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 1, 1, 1, ' ', 0)
 
 	first := true
-	for _, pair := range pairs {
+	for _, pair := range vis.sortedPairs() {
 		x, y := pair[0], pair[1]
 		v := vis.matches[pair]
 
@@ -113,7 +227,7 @@ func (vis visualizer) String() string {
 			io.WriteString(tw, "FAIL:\t")
 		}
 
-		io.WriteString(tw, x.String())
+		io.WriteString(tw, nameX(x))
 
 		if v.skipped {
 			io.WriteString(tw, "\t..\t")
@@ -121,16 +235,38 @@ func (vis visualizer) String() string {
 			io.WriteString(tw, "\t->\t")
 		}
 
-		io.WriteString(tw, y.String())
+		io.WriteString(tw, nameY(y))
 
-		if v.reason != "" {
+		if n := note(x, y, v); n != "" {
 			io.WriteString(tw, "\t// ")
-			io.WriteString(tw, v.reason)
+			io.WriteString(tw, n)
 		}
 	}
 
 	tw.Flush()
-	return b.String()
+	return &b
+}
+
+// sortedPairs returns the matched pairs sorted by the positions of X and then
+// Y. Missing entries come last.
+func (vis visualizer) sortedPairs() [][2]entry {
+	pairs := slices.Collect(maps.Keys(vis.matches))
+	slices.SortFunc(pairs, func(a, b [2]entry) int {
+		if cmp := cmpValidWinsInvalid(a[0].Pos(), b[0].Pos()); cmp != 0 {
+			return cmp
+		}
+		if cmp := cmpAboveWinsBelow(a[0].Pos(), b[0].Pos()); cmp != 0 {
+			return cmp
+		}
+		if cmp := cmpValidWinsInvalid(a[1].Pos(), b[1].Pos()); cmp != 0 {
+			return cmp
+		}
+		if cmp := cmpAboveWinsBelow(a[1].Pos(), b[1].Pos()); cmp != 0 {
+			return cmp
+		}
+		return 0
+	})
+	return pairs
 }
 
 func cmpValidWinsInvalid(a, b token.Pos) int {
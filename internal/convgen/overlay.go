@@ -0,0 +1,49 @@
+package convgeninternal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ReadOverlay reads an overlay file in the format of "go build -overlay":
+//
+//	{"Replace": {"/path/to/main.go": "/tmp/unsaved/main.go"}}
+//
+// It returns a map from file paths to the contents of their replacements to
+// use as [Options.Overlay]. Relative paths are resolved against wd.
+//
+// Unlike the go command, an empty replacement, which means to delete the file,
+// is not supported.
+func ReadOverlay(wd, path string) (map[string][]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overlay: %w", err)
+	}
+
+	var overlay struct{ Replace map[string]string }
+	if err := json.Unmarshal(data, &overlay); err != nil {
+		return nil, fmt.Errorf("failed to parse overlay %s: %w", path, err)
+	}
+
+	abs := func(path string) string {
+		if filepath.IsAbs(path) {
+			return filepath.Clean(path)
+		}
+		return filepath.Join(wd, path)
+	}
+
+	files := make(map[string][]byte, len(overlay.Replace))
+	for from, to := range overlay.Replace {
+		if to == "" {
+			return nil, fmt.Errorf("failed to read overlay %s: deleting %s is not supported", path, from)
+		}
+		content, err := os.ReadFile(abs(to))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read overlay %s: %w", path, err)
+		}
+		files[abs(from)] = content
+	}
+	return files, nil
+}
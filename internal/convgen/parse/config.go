@@ -35,6 +35,8 @@ type Config struct {
 
 	RenamersX      []func(string, string) string
 	RenamersY      []func(string, string) string
+	RenamersAtX    []token.Pos
+	RenamersAtY    []token.Pos
 	CommonFindersX []func([]string) string
 	CommonFindersY []func([]string) string
 
@@ -56,23 +58,34 @@ type Config struct {
 	DiscoverGettersEnabled bool
 	DiscoverGettersPrefix  string
 	DiscoverGettersSuffix  string
+	DiscoverGettersAt      token.Pos
 
 	DiscoverSettersEnabled bool
 	DiscoverSettersPrefix  string
 	DiscoverSettersSuffix  string
+	DiscoverSettersAt      token.Pos
 
 	DiscoverNestedX []Path
 	DiscoverNestedY []Path
 
-	ForStruct *Config
-	ForUnion  *Config
-	ForEnum   *Config
+	ForStruct   *Config
+	ForUnion    *Config
+	ForEnum     *Config
+	ForStructAt []token.Pos
+	ForUnionAt  []token.Pos
+	ForEnumAt   []token.Pos
+
+	// inheritedFuncs is the number of leading Funcs inherited from the base
+	// module by convgen.Extend. They can be overridden.
+	inheritedFuncs int
 }
 
 func (cfg Config) Fork() Config {
 	// Fork rename options to allow overriding
 	cfg.RenamersX = slices.Clone(cfg.RenamersX)
 	cfg.RenamersY = slices.Clone(cfg.RenamersY)
+	cfg.RenamersAtX = slices.Clone(cfg.RenamersAtX)
+	cfg.RenamersAtY = slices.Clone(cfg.RenamersAtY)
 	cfg.CommonFindersX = slices.Clone(cfg.CommonFindersX)
 	cfg.CommonFindersY = slices.Clone(cfg.CommonFindersY)
 
@@ -100,6 +113,8 @@ func (cfg *Config) Update(other Config) {
 
 	cfg.RenamersX = append(cfg.RenamersX, other.RenamersX...)
 	cfg.RenamersY = append(cfg.RenamersY, other.RenamersY...)
+	cfg.RenamersAtX = append(cfg.RenamersAtX, other.RenamersAtX...)
+	cfg.RenamersAtY = append(cfg.RenamersAtY, other.RenamersAtY...)
 	cfg.CommonFindersX = append(cfg.CommonFindersX, other.CommonFindersX...)
 	cfg.CommonFindersY = append(cfg.CommonFindersY, other.CommonFindersY...)
 
@@ -127,12 +142,74 @@ func (cfg *Config) Update(other Config) {
 		cfg.DiscoverGettersEnabled = true
 		cfg.DiscoverGettersPrefix = other.DiscoverGettersPrefix
 		cfg.DiscoverGettersSuffix = other.DiscoverGettersSuffix
+		cfg.DiscoverGettersAt = other.DiscoverGettersAt
 	}
 
 	if other.DiscoverSettersEnabled {
 		cfg.DiscoverSettersEnabled = true
 		cfg.DiscoverSettersPrefix = other.DiscoverSettersPrefix
 		cfg.DiscoverSettersSuffix = other.DiscoverSettersSuffix
+		cfg.DiscoverSettersAt = other.DiscoverSettersAt
+	}
+}
+
+// Inherit returns a deep copy of the module config for a module extending it by
+// [convgen.Extend]. The positions of For* qualifiers are not inherited, so that
+// ineffective qualifiers are reported only in the module declaring them.
+func (cfg Config) Inherit() Config {
+	c := cfg
+	c.Funcs = slices.Clone(cfg.Funcs)
+	c.FuncExprs = slices.Clone(cfg.FuncExprs)
+	c.ErrWraps = slices.Clone(cfg.ErrWraps)
+	c.RenamersX = slices.Clone(cfg.RenamersX)
+	c.RenamersY = slices.Clone(cfg.RenamersY)
+	c.RenamersAtX = slices.Clone(cfg.RenamersAtX)
+	c.RenamersAtY = slices.Clone(cfg.RenamersAtY)
+	c.CommonFindersX = slices.Clone(cfg.CommonFindersX)
+	c.CommonFindersY = slices.Clone(cfg.CommonFindersY)
+	c.MatchFuncs = maps.Clone(cfg.MatchFuncs)
+
+	inherit := func(cfg *Config) *Config {
+		if cfg == nil {
+			return nil
+		}
+		c := cfg.Inherit()
+		return &c
+	}
+	c.ForStruct = inherit(cfg.ForStruct)
+	c.ForUnion = inherit(cfg.ForUnion)
+	c.ForEnum = inherit(cfg.ForEnum)
+	c.ForStructAt = nil
+	c.ForUnionAt = nil
+	c.ForEnumAt = nil
+
+	c.inheritedFuncs = len(c.Funcs)
+	return c
+}
+
+// overrideFuncs removes the functions inherited from the base module which are
+// overridden by functions of the same types imported by the module itself.
+func (cfg *Config) overrideFuncs() {
+	n := cfg.inheritedFuncs
+	var funcs []typeinfo.Func
+	var exprs []ast.Expr
+	for i, fn := range cfg.Funcs {
+		if i < n && slices.ContainsFunc(cfg.Funcs[n:], func(other typeinfo.Func) bool {
+			return types.Identical(fn.X().Type(), other.X().Type()) && types.Identical(fn.Y().Type(), other.Y().Type())
+		}) {
+			continue
+		}
+		funcs = append(funcs, fn)
+		exprs = append(exprs, cfg.FuncExprs[i])
+	}
+	cfg.Funcs = funcs
+	cfg.FuncExprs = exprs
+	cfg.inheritedFuncs = 0
+
+	for _, c := range []*Config{cfg.ForStruct, cfg.ForUnion, cfg.ForEnum} {
+		if c != nil {
+			c.overrideFuncs()
+		}
 	}
 }
 
@@ -195,6 +272,7 @@ func (p *Parser) ParseConfig(cfg *Config, args []ast.Expr, parsers parsers) erro
 			if cfg.ForStruct == nil {
 				cfg.ForStruct = &Config{}
 			}
+			cfg.ForStructAt = append(cfg.ForStructAt, call.Pos())
 			if err := p.ParseConfig(cfg.ForStruct, call.Args, parsers); err != nil {
 				errs = errors.Join(errs, err)
 			}
@@ -203,6 +281,7 @@ func (p *Parser) ParseConfig(cfg *Config, args []ast.Expr, parsers parsers) erro
 			if cfg.ForUnion == nil {
 				cfg.ForUnion = &Config{}
 			}
+			cfg.ForUnionAt = append(cfg.ForUnionAt, call.Pos())
 			if err := p.ParseConfig(cfg.ForUnion, call.Args, parsers); err != nil {
 				errs = errors.Join(errs, err)
 			}
@@ -211,6 +290,7 @@ func (p *Parser) ParseConfig(cfg *Config, args []ast.Expr, parsers parsers) erro
 			if cfg.ForEnum == nil {
 				cfg.ForEnum = &Config{}
 			}
+			cfg.ForEnumAt = append(cfg.ForEnumAt, call.Pos())
 			if err := p.ParseConfig(cfg.ForEnum, call.Args, parsers); err != nil {
 				errs = errors.Join(errs, err)
 			}
@@ -236,6 +316,22 @@ func (p *Parser) ParseOption(cfg *Config, call *ast.CallExpr, ps parsers) error
 		return p.ParseOptionImportFunc(cfg, call, false)
 	case "ImportFuncErr":
 		return p.ParseOptionImportFunc(cfg, call, true)
+	case "ImportFuncGeneric":
+		return p.ParseOptionImportFuncGeneric(cfg, call, false)
+	case "ImportFuncGenericErr":
+		return p.ParseOptionImportFuncGeneric(cfg, call, true)
+	case "ImportFuncOk":
+		return p.ParseOptionImportFuncOk(cfg, call)
+	case "ImportFuncOkOr":
+		return p.ParseOptionImportFuncOkOr(cfg, call)
+	case "ImportFuncsBySample":
+		return p.ParseOptionImportFuncsBySample(cfg, call)
+	case "ImportModule":
+		return p.ParseOptionImportModule(cfg, call)
+	case "Extend":
+		return codefmt.Errorf(p, call, "convgen.Extend must be the first option of convgen.Module")
+	case "ImportFuncReset":
+		return p.ParseOptionImportFuncReset(cfg, call)
 	case "ImportErrWrap":
 		return p.ParseOptionImportErrWrap(cfg, call)
 	case "ImportErrWrapReset":
@@ -306,6 +402,114 @@ func (p *Parser) ParseOptionImportFunc(c *Config, call *ast.CallExpr, hasErr boo
 	return nil
 }
 
+func (p *Parser) ParseOptionImportFuncGeneric(c *Config, call *ast.CallExpr, hasErr bool) error {
+	expr, err := needArgs1(p, call)
+	if err != nil {
+		return err
+	}
+
+	fn, err := p.ParseGenericFunc(expr, hasErr)
+	if err != nil {
+		return err
+	}
+
+	c.Funcs = append(c.Funcs, fn.WithPos(call.Pos()))
+	c.FuncExprs = append(c.FuncExprs, call)
+	return nil
+}
+
+// OkFunc is a comma-ok function imported by [convgen.ImportFuncOkOr]. Fallback
+// is assigned to the output instead when the function reports false.
+type OkFunc struct {
+	typeinfo.Func
+	Fallback ast.Expr
+}
+
+// WithPos returns a copy of the [OkFunc] with the given position.
+func (fn OkFunc) WithPos(pos token.Pos) typeinfo.Func {
+	return OkFunc{fn.Func.WithPos(pos), fn.Fallback}
+}
+
+func (p *Parser) ParseOptionImportFuncOk(c *Config, call *ast.CallExpr) error {
+	expr, err := needArgs1(p, call)
+	if err != nil {
+		return err
+	}
+
+	fn, err := p.ParseOkFunc(expr)
+	if err != nil {
+		return err
+	}
+
+	c.Funcs = append(c.Funcs, fn.WithPos(call.Pos()))
+	c.FuncExprs = append(c.FuncExprs, call)
+	return nil
+}
+
+func (p *Parser) ParseOptionImportFuncOkOr(c *Config, call *ast.CallExpr) error {
+	expr, fallback, err := needArgs2(p, call)
+	if err != nil {
+		return err
+	}
+
+	fn, err := p.ParseOkFunc(expr)
+	if err != nil {
+		return err
+	}
+
+	c.Funcs = append(c.Funcs, OkFunc{fn, fallback}.WithPos(call.Pos()))
+	c.FuncExprs = append(c.FuncExprs, call)
+	return nil
+}
+
+func (p *Parser) ParseOptionImportFuncsBySample(c *Config, call *ast.CallExpr) error {
+	expr, err := needArgs1(p, call)
+	if err != nil {
+		return err
+	}
+
+	fns, err := p.ParseSampleFuncs(expr)
+	if err != nil {
+		return err
+	}
+
+	for _, fn := range fns {
+		c.Funcs = append(c.Funcs, fn.WithPos(call.Pos()))
+		c.FuncExprs = append(c.FuncExprs, call)
+	}
+	return nil
+}
+
+func (p *Parser) ParseOptionImportFuncReset(c *Config, call *ast.CallExpr) error {
+	err := needArgs0(p, call)
+	if err != nil {
+		return err
+	}
+
+	c.Funcs = nil
+	c.FuncExprs = nil
+	c.inheritedFuncs = 0
+	return nil
+}
+
+func (p *Parser) ParseOptionImportModule(c *Config, call *ast.CallExpr) error {
+	expr, err := needArgs1(p, call)
+	if err != nil {
+		return err
+	}
+
+	fns, err := p.ParseImportedModule(expr)
+	if err != nil {
+		return err
+	}
+
+	for _, fn := range fns {
+		c.Funcs = append(c.Funcs, fn.WithPos(call.Pos()))
+		c.FuncExprs = append(c.FuncExprs, call)
+	}
+	return nil
+}
+
 func (p *Parser) ParseOptionImportErrWrap(c *Config, call *ast.CallExpr) error {
 	expr, err := needArgs1(p, call)
 	if err != nil {
@@ -343,10 +547,12 @@ func (p *Parser) ParseOptionRenameBool(c *Config, call *ast.CallExpr, rename fun
 
 	if x {
 		c.RenamersX = append(c.RenamersX, func(s, _ string) string { return rename(s) })
+		c.RenamersAtX = append(c.RenamersAtX, call.Pos())
 		c.CommonFindersX = append(c.CommonFindersX, nil)
 	}
 	if y {
 		c.RenamersY = append(c.RenamersY, func(s, _ string) string { return rename(s) })
+		c.RenamersAtY = append(c.RenamersAtY, call.Pos())
 		c.CommonFindersY = append(c.CommonFindersY, nil)
 	}
 	return nil
@@ -360,10 +566,12 @@ func (p *Parser) ParseOptionRenameString(c *Config, call *ast.CallExpr, rename f
 
 	if x != "" {
 		c.RenamersX = append(c.RenamersX, func(s, _ string) string { return rename(s, x) })
+		c.RenamersAtX = append(c.RenamersAtX, call.Pos())
 		c.CommonFindersX = append(c.CommonFindersX, nil)
 	}
 	if y != "" {
 		c.RenamersY = append(c.RenamersY, func(s, _ string) string { return rename(s, y) })
+		c.RenamersAtY = append(c.RenamersAtY, call.Pos())
 		c.CommonFindersY = append(c.CommonFindersY, nil)
 	}
 	return nil
@@ -377,10 +585,12 @@ func (p *Parser) ParseOptionRenameCommon(c *Config, call *ast.CallExpr, find fun
 
 	if x {
 		c.RenamersX = append(c.RenamersX, rename)
+		c.RenamersAtX = append(c.RenamersAtX, call.Pos())
 		c.CommonFindersX = append(c.CommonFindersX, find)
 	}
 	if y {
 		c.RenamersY = append(c.RenamersY, rename)
+		c.RenamersAtY = append(c.RenamersAtY, call.Pos())
 		c.CommonFindersY = append(c.CommonFindersY, find)
 	}
 	return nil
@@ -394,6 +604,8 @@ func (p *Parser) ParseOptionRenameReplace(c *Config, call *ast.CallExpr) error {
 
 	c.RenamersX = append(c.RenamersX, func(s, _ string) string { return strings.ReplaceAll(s, oldX, newX) })
 	c.RenamersY = append(c.RenamersY, func(s, _ string) string { return strings.ReplaceAll(s, oldY, newY) })
+	c.RenamersAtX = append(c.RenamersAtX, call.Pos())
+	c.RenamersAtY = append(c.RenamersAtY, call.Pos())
 	c.CommonFindersX = append(c.CommonFindersX, nil)
 	c.CommonFindersY = append(c.CommonFindersY, nil)
 	return nil
@@ -420,6 +632,8 @@ func (p *Parser) ParseOptionRenameReplaceRegexp(c *Config, call *ast.CallExpr) e
 
 	c.RenamersX = append(c.RenamersX, func(s, _ string) string { return regexpX.ReplaceAllString(s, replX) })
 	c.RenamersY = append(c.RenamersY, func(s, _ string) string { return regexpY.ReplaceAllString(s, replY) })
+	c.RenamersAtX = append(c.RenamersAtX, call.Pos())
+	c.RenamersAtY = append(c.RenamersAtY, call.Pos())
 	c.CommonFindersX = append(c.CommonFindersX, nil)
 	c.CommonFindersY = append(c.CommonFindersY, nil)
 	return nil
@@ -433,10 +647,12 @@ func (p *Parser) ParseOptionRenameReset(c *Config, call *ast.CallExpr) error {
 
 	if x {
 		c.RenamersX = nil
+		c.RenamersAtX = nil
 		c.CommonFindersX = nil
 	}
 	if y {
 		c.RenamersY = nil
+		c.RenamersAtY = nil
 		c.CommonFindersY = nil
 	}
 	return nil
@@ -463,6 +679,7 @@ func (p *Parser) ParseOptionDiscoverGetters(c *Config, call *ast.CallExpr) error
 	c.DiscoverGettersEnabled = true
 	c.DiscoverGettersPrefix = prefix
 	c.DiscoverGettersSuffix = suffix
+	c.DiscoverGettersAt = call.Pos()
 	return nil
 }
 
@@ -475,6 +692,7 @@ func (p *Parser) ParseOptionDiscoverSetters(c *Config, call *ast.CallExpr) error
 	c.DiscoverSettersEnabled = true
 	c.DiscoverSettersPrefix = prefix
 	c.DiscoverSettersSuffix = suffix
+	c.DiscoverSettersAt = call.Pos()
 	return nil
 }
 
@@ -492,11 +710,13 @@ func (p *Parser) ParseOptionDiscoverFieldsOnly(c *Config, call *ast.CallExpr) er
 		c.DiscoverGettersEnabled = false
 		c.DiscoverGettersPrefix = ""
 		c.DiscoverGettersSuffix = ""
+		c.DiscoverGettersAt = token.NoPos
 	}
 	if y {
 		c.DiscoverSettersEnabled = false
 		c.DiscoverSettersPrefix = ""
 		c.DiscoverSettersSuffix = ""
+		c.DiscoverSettersAt = token.NoPos
 	}
 	return nil
 }
@@ -0,0 +1,135 @@
+package parse
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"github.com/sublee/convgen/internal/codefmt"
+)
+
+// directiveName finds the identifier of the directive name and the qualifier
+// of the Convgen package in the call expression.
+//
+//	convgen.Struct[X, Y](...)
+//	^^^^^^^ ^^^^^^
+//	qualifier, name
+//
+// The qualifier is empty if the Convgen package is dot-imported.
+func directiveName(call *ast.CallExpr) (name *ast.Ident, qualifier string, ok bool) {
+	fun := ast.Unparen(call.Fun)
+	switch x := fun.(type) {
+	case *ast.IndexExpr:
+		fun = x.X
+	case *ast.IndexListExpr:
+		fun = x.X
+	}
+
+	switch x := fun.(type) {
+	case *ast.Ident:
+		return x, "", true
+	case *ast.SelectorExpr:
+		if pkg, ok := x.X.(*ast.Ident); ok {
+			return x.Sel, pkg.Name, true
+		}
+	}
+	return nil, "", false
+}
+
+// formatDirective formats a directive call with the given qualifier, like
+// "convgen.Match(X{}.A, Y{}.B)".
+func formatDirective(qualifier, name string, args ...string) string {
+	if qualifier != "" {
+		name = qualifier + "." + name
+	}
+	return fmt.Sprintf("%s(%s)", name, strings.Join(args, ", "))
+}
+
+// insertArg returns an edit that appends an argument to the call expression.
+// The argument is inserted after the last argument rather than before the
+// closing parenthesis to keep a trailing comma valid.
+func insertArg(call *ast.CallExpr, arg string) codefmt.Edit {
+	if len(call.Args) == 0 {
+		return codefmt.Edit{Pos: call.Rparen, End: call.Rparen, NewText: arg}
+	}
+	end := call.Args[len(call.Args)-1].End()
+	return codefmt.Edit{Pos: end, End: end, NewText: ", " + arg}
+}
+
+// FixAddOption returns a fix that adds a directive option to the injector call.
+// For example, FixAddOption("Match", "X{}.A", "Y{}.B") inserts
+// "convgen.Match(X{}.A, Y{}.B)". It fails if the injector is not explicit.
+func (inj Injector) FixAddOption(message, name string, args ...string) (codefmt.Fix, bool) {
+	if !inj.Explicit() || inj.call == nil {
+		return codefmt.Fix{}, false
+	}
+
+	_, qualifier, ok := directiveName(inj.call)
+	if !ok {
+		return codefmt.Fix{}, false
+	}
+
+	opt := formatDirective(qualifier, name, args...)
+	return codefmt.Fix{
+		Message: message,
+		Edits:   []codefmt.Edit{insertArg(inj.call, opt)},
+	}, true
+}
+
+// FixErr returns a fix that switches the root injector to its Err variant, for
+// example, from convgen.Struct to convgen.StructErr. It fails if the root
+// injector already returns an error.
+func (inj Injector) FixErr() (codefmt.Fix, bool) {
+	root := inj.Root()
+	if root.HasErr() || root.call == nil {
+		return codefmt.Fix{}, false
+	}
+
+	id, _, ok := directiveName(root.call)
+	if !ok {
+		return codefmt.Fix{}, false
+	}
+
+	return codefmt.Fix{
+		Message: fmt.Sprintf("Use %sErr", id.Name),
+		Edits: []codefmt.Edit{{
+			Pos:     id.Pos(),
+			End:     id.End(),
+			NewText: id.Name + "Err",
+		}},
+	}, true
+}
+
+// FixImportFunc returns a fix that imports a stub function to convert x to y
+// into the module of the injector. If the injector has a nil module, the nil
+// is replaced with an inline module.
+func (inj Injector) FixImportFunc(x, y types.Type) (codefmt.Fix, bool) {
+	root := inj.Root()
+	if root.call == nil || len(root.call.Args) == 0 {
+		return codefmt.Fix{}, false
+	}
+
+	_, qualifier, ok := directiveName(root.call)
+	if !ok {
+		return codefmt.Fix{}, false
+	}
+
+	stub := codefmt.Sprintf(inj, "func(%t) %t { panic(\"TODO: convert %t to %t\") }", x, y, x, y)
+	opt := formatDirective(qualifier, "ImportFunc", stub)
+	fix := codefmt.Fix{Message: codefmt.Sprintf(inj, "Import a stub function to convert %t to %t", x, y)}
+
+	if inj.Module.call != nil {
+		fix.Edits = append(fix.Edits, insertArg(inj.Module.call, opt))
+		return fix, true
+	}
+
+	// Nil module: replace nil with convgen.Module(convgen.ImportFunc(...))
+	arg := root.call.Args[0]
+	fix.Edits = append(fix.Edits, codefmt.Edit{
+		Pos:     arg.Pos(),
+		End:     arg.End(),
+		NewText: formatDirective(qualifier, "Module", opt),
+	})
+	return fix, true
+}
@@ -22,13 +22,21 @@ type Injector struct {
 	Module *Module
 	Config Config
 
+	// Funcs holds the functions imported by the converter itself by
+	// convgen.ImportFunc or convgen.ImportFuncErr. They take precedence over
+	// the functions of the module and also apply to the subconverters reached
+	// from the converter. It is nil if the converter imports no function.
+	Funcs *typeinfo.Lookup[typeinfo.Func]
+
 	Struct      bool
 	Union       bool
 	Enum        bool
 	EnumUnknown *types.Const
 
-	pkg *packages.Package
-	pos token.Pos
+	pkg   *packages.Package
+	pos   token.Pos
+	call  *ast.CallExpr
+	usage *Usage
 
 	Doc     *ast.CommentGroup
 	Comment *ast.CommentGroup
@@ -44,6 +52,17 @@ func (inj Injector) Pkg() *packages.Package { return inj.pkg }
 // implements [codefmt.Poser] by this method.
 func (inj Injector) Pos() token.Pos { return inj.pos }
 
+// Call returns the call expression of the injector.
+func (inj Injector) Call() *ast.CallExpr { return inj.call }
+
+// Usage returns where to record options which took effect while building the
+// converter. It may be nil.
+func (inj Injector) Usage() *Usage { return inj.usage }
+
+// Explicit reports whether the injector is explicitly injected by user code
+// rather than forked for a subconverter.
+func (inj Injector) Explicit() bool { return inj.parent == nil }
+
 // String returns a string representation of the injector. For example,
 // "convgen.Struct[Foo, Bar]".
 func (inj Injector) String() string {
@@ -74,13 +93,16 @@ func (inj Injector) Fork(x, y typeinfo.Type) Injector {
 
 		Module: inj.Module,
 		Config: inj.Config.Fork(),
+		Funcs:  inj.Funcs,
 
 		Struct: inj.Struct,
 		Union:  inj.Union,
 		Enum:   inj.Enum,
 
-		pkg: inj.pkg,
-		pos: inj.pos,
+		pkg:   inj.pkg,
+		pos:   inj.pos,
+		call:  inj.call,
+		usage: inj.usage,
 
 		parent: &inj,
 	}
@@ -199,6 +221,8 @@ func (p *Parser) parseInjector(id *ast.Ident, call *ast.CallExpr, doc, comment *
 	inj := Injector{
 		pkg:     p.Pkg(),
 		pos:     call.Pos(),
+		call:    call,
+		usage:   p.usage,
 		Doc:     doc,
 		Comment: comment,
 	}
@@ -266,9 +290,17 @@ func (p *Parser) parseInjector(id *ast.Ident, call *ast.CallExpr, doc, comment *
 	// Parse config
 	cfg.DiscoverBySamplePkgX = inj.X().Pkg()
 	cfg.DiscoverBySamplePkgY = inj.Y().Pkg()
+	numFuncs := len(cfg.Funcs)
 	errs = errors.Join(errs, p.ParseConfig(&cfg, opts, parsers))
 	inj.Config = cfg
 
+	// Register functions imported by the converter itself
+	if len(cfg.Funcs) > numFuncs {
+		funcs, err := p.newModuleLookup(Config{Funcs: cfg.Funcs[numFuncs:], FuncExprs: cfg.FuncExprs[numFuncs:]}, nil)
+		inj.Funcs = funcs
+		errs = errors.Join(errs, err)
+	}
+
 	// Register into the module
 	if oldFn, ok := mod.Put(inj); !ok {
 		if oldInj, ok := oldFn.(Injector); ok {
@@ -3,9 +3,13 @@ package parse
 import (
 	"errors"
 	"go/ast"
+	"go/parser"
 	"go/token"
+	"go/types"
 	"iter"
+	"path/filepath"
 	"slices"
+	"strconv"
 
 	"github.com/sublee/convgen/internal/codefmt"
 	"github.com/sublee/convgen/internal/typeinfo"
@@ -32,24 +36,102 @@ type Module struct {
 	LookupForStruct *typeinfo.Lookup[typeinfo.Func]
 	LookupForUnion  *typeinfo.Lookup[typeinfo.Func]
 	LookupForEnum   *typeinfo.Lookup[typeinfo.Func]
+
+	// call is the convgen.Module call expression. It is nil for a nil module.
+	call *ast.CallExpr
+
+	// base is the module extended by convgen.Extend, if any.
+	base *Module
+}
+
+// Get returns the function registered in the module to convert x to y. If the
+// module has none, it falls back to the explicit converters of the base
+// modules. Functions imported by the base modules are not looked up because
+// they are already inherited unless overridden or reset.
+func (m *Module) Get(x, y typeinfo.Type) (typeinfo.Func, bool) {
+	if fn, ok := m.Lookup.Get(x, y); ok {
+		return fn, true
+	}
+	for base := m.base; base != nil; base = base.base {
+		if fn, ok := base.Lookup.Get(x, y); ok {
+			if _, ok := fn.(Injector); ok {
+				return fn, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// Instantiate returns the instances of the generic functions imported by
+// [convgen.ImportFuncGeneric] or [convgen.ImportFuncGenericErr] which convert
+// x to y, in the order of their imports. More than one instance means the
+// instantiation is ambiguous.
+func (m *Module) Instantiate(x, y typeinfo.Type) []typeinfo.Func {
+	var insts []typeinfo.Func
+	for _, fn := range m.Config.Funcs {
+		if !typeinfo.IsGeneric(fn) {
+			continue
+		}
+		if inst, ok := typeinfo.Instantiate(fn, x, y); ok {
+			insts = append(insts, inst)
+		}
+	}
+	return insts
 }
 
 // ParseModules finds and parses all convgen.Module calls in the parsed files.
+//
+// A module may extend another module declared later by [convgen.Extend]. So
+// the declarations are collected first and a base module is parsed before the
+// modules extending it.
 func (p *Parser) ParseModules() (map[token.Pos]*Module, error) {
 	var errs error
 	mods := make(map[token.Pos]*Module)
 
+	type decl struct {
+		name string
+		call *ast.CallExpr
+	}
+	decls := make(map[token.Pos]decl)
+	var order []token.Pos
 	for _, file := range p.ConvgenGoFiles() {
 		for id, call := range p.FindModules(file) {
 			name := id.Name
 			if name == "_" {
 				name = ""
 			}
+			decls[id.Pos()] = decl{name, call}
+			order = append(order, id.Pos())
+		}
+	}
 
-			mod, err := p.ParseModule(call, name)
-			mods[id.Pos()] = mod
-			errs = errors.Join(errs, err)
+	parsing := make(map[token.Pos]bool)
+	var parse func(pos token.Pos) *Module
+	resolve := func(id *ast.Ident) (*Module, error) {
+		pos := p.Pkg().TypesInfo.ObjectOf(id).Pos()
+		if _, ok := decls[pos]; !ok {
+			return nil, codefmt.Errorf(p, id, "cannot find %q module declared by convgen.Module", id.Name)
+		}
+		if parsing[pos] && mods[pos] == nil {
+			// The type checker reports such an initialization cycle first,
+			// but a cycle must not recurse infinitely.
+			return nil, codefmt.Errorf(p, id, "cannot extend %q module cyclically", id.Name)
 		}
+		return parse(pos), nil
+	}
+	parse = func(pos token.Pos) *Module {
+		if mod, ok := mods[pos]; ok {
+			return mod
+		}
+		parsing[pos] = true
+
+		mod, err := p.ParseModule(decls[pos].call, decls[pos].name, resolve)
+		mods[pos] = mod
+		errs = errors.Join(errs, err)
+		return mod
+	}
+	for _, pos := range order {
+		parse(pos)
 	}
 
 	return mods, errs
@@ -90,9 +172,13 @@ func (p *Parser) FindModules(file *ast.File) iter.Seq2[*ast.Ident, *ast.CallExpr
 	}
 }
 
+// moduleResolver returns the package-level module declared by the identifier.
+// It finds the base module of [convgen.Extend].
+type moduleResolver func(id *ast.Ident) (*Module, error)
+
 // ParseModule parses a [convgen.Module] call expression and returns a new
 // module.
-func (p *Parser) ParseModule(call *ast.CallExpr, name string) (*Module, error) {
+func (p *Parser) ParseModule(call *ast.CallExpr, name string, resolve moduleResolver) (*Module, error) {
 	// Chain of For* after NewModule
 	calls := []*ast.CallExpr{call}
 	for {
@@ -111,11 +197,27 @@ func (p *Parser) ParseModule(call *ast.CallExpr, name string) (*Module, error) {
 	}
 	slices.Reverse(calls)
 
+	// Inherit the config of the base module
 	var cfg Config
+	var base *Module
 	var errs error
-	if err := p.ParseConfig(&cfg, calls[0].Args, nil); err != nil {
+	args := calls[0].Args
+	if len(args) != 0 {
+		if call, ok := ast.Unparen(args[0]).(*ast.CallExpr); ok && p.IsDirective(call, "Extend") {
+			mod, err := p.parseExtend(call, resolve)
+			if err == nil {
+				base = mod
+				cfg = base.Config.Inherit()
+			}
+			errs = errors.Join(errs, err)
+			args = args[1:]
+		}
+	}
+
+	if err := p.ParseConfig(&cfg, args, nil); err != nil {
 		errs = errors.Join(errs, err)
 	}
+	cfg.overrideFuncs()
 
 	for _, call := range calls[1:] {
 		switch call.Fun.(*ast.SelectorExpr).Sel.Name {
@@ -153,7 +255,7 @@ func (p *Parser) ParseModule(call *ast.CallExpr, name string) (*Module, error) {
 		errs = errors.Join(errs, err)
 	}
 
-	return &Module{Name: name, Config: cfg, Lookup: lookup}, errs
+	return &Module{Name: name, Config: cfg, Lookup: lookup, call: calls[0], base: base}, errs
 }
 
 func (p *Parser) newModuleLookup(cfg Config, old *typeinfo.Lookup[typeinfo.Func]) (*typeinfo.Lookup[typeinfo.Func], error) {
@@ -167,13 +269,25 @@ func (p *Parser) newModuleLookup(cfg Config, old *typeinfo.Lookup[typeinfo.Func]
 	}
 
 	for i, fn := range cfg.Funcs {
-		if oldFn, ok := lookup.Put(fn); !ok {
-			err := codefmt.Errorf(p, cfg.FuncExprs[i], `duplicate %t to %t converter
+		oldFn, ok := lookup.Put(fn)
+		if ok {
+			continue
+		}
+
+		var err error
+		if call, ok := cfg.FuncExprs[i].(*ast.CallExpr); ok && p.IsDirective(call, "ImportFuncsBySample") {
+			// The call imports many functions, so name the duplicate one.
+			err = codefmt.Errorf(p, call, `duplicate %t to %t converter %o
+	previous import of %o at %b`,
+				fn.X(), fn.Y(), fn,
+				oldFn, oldFn)
+		} else {
+			err = codefmt.Errorf(p, cfg.FuncExprs[i], `duplicate %t to %t converter
 	previous import of %o at %b`,
 				fn.X(), fn.Y(),
 				oldFn, oldFn)
-			errs = errors.Join(errs, err)
 		}
+		errs = errors.Join(errs, err)
 	}
 
 	return lookup, errs
@@ -194,7 +308,12 @@ func (p *Parser) ParseModuleArg(expr ast.Expr, mods map[token.Pos]*Module) (*Mod
 	// implicit converters. The implicit converters will inherit the module's
 	// configuration.
 	if call, ok := expr.(*ast.CallExpr); ok && p.IsDirective(call, "Module") {
-		return p.ParseModule(call, "")
+		return p.ParseModule(call, "", func(id *ast.Ident) (*Module, error) {
+			if mod, ok := mods[p.Pkg().TypesInfo.ObjectOf(id).Pos()]; ok {
+				return mod, nil
+			}
+			return nil, codefmt.Errorf(p, id, "cannot find %q module declared by convgen.Module", id.Name)
+		})
 	}
 
 	// Validate identifier
@@ -237,7 +356,184 @@ func (p *Parser) ParseModuleArg(expr ast.Expr, mods map[token.Pos]*Module) (*Mod
 	return mod, nil
 }
 
+// parseExtend parses a [convgen.Extend] call expression and returns the base
+// module.
+func (p *Parser) parseExtend(call *ast.CallExpr, resolve moduleResolver) (*Module, error) {
+	expr, err := needArgs1(p, call)
+	if err != nil {
+		return nil, err
+	}
+
+	id, ok := ast.Unparen(expr).(*ast.Ident)
+	if !ok || p.IsNil(id) {
+		return nil, codefmt.Errorf(p, expr, "base module must be package-level variable")
+	}
+	return resolve(id)
+}
+
 // NilModule returns a new empty module with no configuration.
 func NilModule() *Module {
 	return &Module{Lookup: typeinfo.NewLookup[typeinfo.Func]()}
 }
+
+// ParseImportedModule parses a [convgen.ImportModule] argument, a package-level
+// module variable of another package, and returns the exported explicit
+// converters which belong to the module.
+//
+// The export data of the package does not tell which module a converter
+// belongs to. So the converters are found in the source files of the package
+// loaded with the convgen build tag.
+func (p *Parser) ParseImportedModule(expr ast.Expr) ([]typeinfo.Func, error) {
+	expr = ast.Unparen(expr)
+
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		if id, ok := expr.(*ast.Ident); ok && !p.IsNil(id) {
+			return nil, codefmt.Errorf(p, expr, "cannot import module %q of the same package", id.Name)
+		}
+		return nil, codefmt.Errorf(p, expr, "module must be package-level variable of another package")
+	}
+
+	obj, ok := p.Pkg().TypesInfo.ObjectOf(sel.Sel).(*types.Var)
+	if !ok || obj.Pkg() == nil || obj.Parent() != obj.Pkg().Scope() || !isModuleType(obj.Type()) {
+		return nil, codefmt.Errorf(p, expr, "module must be package-level variable of another package")
+	}
+
+	files, err := p.importedGoFiles(obj.Pkg().Path())
+	if err != nil {
+		return nil, codefmt.Errorf(p, expr, "cannot find source of %s: %s", obj.Pkg().Path(), err.Error())
+	}
+
+	var fns []typeinfo.Func
+	fset := token.NewFileSet()
+	for _, path := range files {
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, codefmt.Errorf(p, expr, "cannot parse %s: %s", filepath.Base(path), err.Error())
+		}
+
+		for name := range findModuleConverters(file, obj.Name()) {
+			conv := obj.Pkg().Scope().Lookup(name)
+			if conv == nil {
+				continue
+			}
+			fn, err := typeinfo.FuncOf[typeinfo.BothXY](conv)
+			if err != nil {
+				return nil, codefmt.Errorf(p, expr, "cannot import %s.%s: %s", obj.Pkg().Name(), name, err.Error())
+			}
+			fns = append(fns, fn)
+		}
+	}
+	return fns, nil
+}
+
+// importedGoFiles returns the Go files of the imported package which are
+// selected with the build tags the package is loaded with. The go/analysis
+// driver does not provide the imports, so it falls back to go/build with the
+// build context of the parser.
+func (p *Parser) importedGoFiles(pkgPath string) ([]string, error) {
+	for _, imp := range p.Pkg().Imports {
+		if imp.PkgPath == pkgPath && len(imp.GoFiles) != 0 {
+			return imp.GoFiles, nil
+		}
+	}
+
+	var srcDir string
+	if len(p.Pkg().Syntax) != 0 {
+		srcDir = filepath.Dir(p.Pkg().Fset.File(p.Pkg().Syntax[0].Pos()).Name())
+	}
+	bp, err := p.ctxt.Import(pkgPath, srcDir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, len(bp.GoFiles))
+	for i, name := range bp.GoFiles {
+		files[i] = filepath.Join(bp.Dir, name)
+	}
+	return files, nil
+}
+
+// findModuleConverters iterates the names of exported package-level converters
+// declared with the module in the file, such as EncodeMoney in:
+//
+//	var EncodeMoney = convgen.Struct[Money, api.Money](Mod)
+//
+// The file is not type-checked, so Convgen directives are recognized by the
+// name of the Convgen import.
+func findModuleConverters(file *ast.File, modName string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		var pkgName string
+		for _, spec := range file.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil || !IsConvgenImport(path) {
+				continue
+			}
+			pkgName = "convgen"
+			if spec.Name != nil {
+				pkgName = spec.Name.Name
+			}
+		}
+		if pkgName == "" {
+			return
+		}
+
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+
+			for _, spec := range gen.Specs {
+				val := spec.(*ast.ValueSpec)
+				for i, id := range val.Names {
+					if len(val.Values) <= i || !id.IsExported() {
+						continue
+					}
+
+					call, ok := ast.Unparen(val.Values[i]).(*ast.CallExpr)
+					if !ok || len(call.Args) == 0 {
+						continue
+					}
+
+					fun := ast.Unparen(call.Fun)
+					switch index := fun.(type) {
+					case *ast.IndexExpr:
+						fun = index.X
+					case *ast.IndexListExpr:
+						fun = index.X
+					}
+					sel, ok := fun.(*ast.SelectorExpr)
+					if !ok {
+						continue
+					}
+					if x, ok := sel.X.(*ast.Ident); !ok || x.Name != pkgName {
+						continue
+					}
+					switch sel.Sel.Name {
+					case "Struct", "StructErr", "Union", "UnionErr", "Enum", "EnumErr":
+					default:
+						continue
+					}
+
+					if mod, ok := ast.Unparen(call.Args[0]).(*ast.Ident); !ok || mod.Name != modName {
+						continue
+					}
+					if !yield(id.Name) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+// isModuleType reports whether t is the type of modules returned by
+// [convgen.Module].
+func isModuleType(t types.Type) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return false
+	}
+	return named.Obj().Name() == "module" && IsConvgenImport(named.Obj().Pkg().Path())
+}
@@ -3,6 +3,7 @@ package parse
 import (
 	"fmt"
 	"go/ast"
+	"go/build"
 	"go/build/constraint"
 	"go/types"
 	"strings"
@@ -24,12 +25,21 @@ func IsConvgenImport(path string) bool {
 }
 
 // Parser parses an AST of the underlying package to collect convgen converters.
-type Parser struct{ pkg *packages.Package }
+type Parser struct {
+	pkg   *packages.Package
+	ctxt  build.Context
+	usage *Usage
+}
 
 func (p *Parser) Pkg() *packages.Package { return p.pkg }
 
-// New creates a new [Parser].
-func New(pkg *packages.Package) (*Parser, error) {
+// Usage returns the usage of options shared by all injectors parsed by this
+// parser.
+func (p *Parser) Usage() *Usage { return p.usage }
+
+// New creates a new [Parser]. The build context selects the files of imported
+// packages which are loaded without their files.
+func New(pkg *packages.Package, ctxt build.Context) (*Parser, error) {
 	if pkg.Name == "" {
 		return nil, fmt.Errorf("need pkg name")
 	}
@@ -48,7 +58,7 @@ func New(pkg *packages.Package) (*Parser, error) {
 	if pkg.TypesInfo == nil {
 		return nil, fmt.Errorf("need pkg types info")
 	}
-	return &Parser{pkg: pkg}, nil
+	return &Parser{pkg: pkg, ctxt: ctxt, usage: NewUsage()}, nil
 }
 
 func (p *Parser) IsNil(expr ast.Expr) bool {
@@ -79,6 +89,39 @@ func (p *Parser) IsNil(expr ast.Expr) bool {
 // return an error as the last return value. The function is used for
 // [convgen.ImportFunc], [convgen.MatchFunc], and their Err variants.
 func (p *Parser) ParseFunc(expr ast.Expr, hasErr bool) (typeinfo.Func, error) {
+	fn, err := p.parseFunc(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	if fn.HasOk() {
+		return nil, codefmt.Errorf(p, expr, "cannot use comma-ok function %c; use convgen.ImportFuncOk", expr) // unreachable
+	}
+	if hasErr && !fn.HasErr() {
+		return nil, codefmt.Errorf(p, expr, "function must return error") // unreachable
+	} else if !hasErr && fn.HasErr() {
+		return nil, codefmt.Errorf(p, expr, "function must not return error") // unreachable
+	}
+	return fn, nil
+}
+
+// ParseOkFunc parses a comma-ok function expression like func(X) (Y, bool).
+// The function is used for [convgen.ImportFuncOk] and [convgen.ImportFuncOkOr].
+func (p *Parser) ParseOkFunc(expr ast.Expr) (typeinfo.Func, error) {
+	fn, err := p.parseFunc(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	if !fn.HasOk() {
+		return nil, codefmt.Errorf(p, expr, "function must return bool as the last value") // unreachable
+	}
+	return fn, nil
+}
+
+// parseFunc parses a declared function or a function literal which converts
+// X to Y.
+func (p *Parser) parseFunc(expr ast.Expr) (typeinfo.Func, error) {
 	expr = ast.Unparen(expr)
 
 	var fn typeinfo.Func
@@ -105,15 +148,124 @@ func (p *Parser) ParseFunc(expr ast.Expr, hasErr bool) (typeinfo.Func, error) {
 		}
 		fn = fn_
 	}
+	return fn, nil
+}
+
+// ParseGenericFunc parses a generic function expression instantiated with
+// placeholder type arguments. It returns the generic function which is not
+// instantiated. The function is used for [convgen.ImportFuncGeneric] and
+// [convgen.ImportFuncGenericErr].
+func (p *Parser) ParseGenericFunc(expr ast.Expr, hasErr bool) (typeinfo.Func, error) {
+	expr = ast.Unparen(expr)
+
+	// Ptr[any] or Convert[any, any]
+	fun := expr
+	switch index := expr.(type) {
+	case *ast.IndexExpr:
+		fun = index.X
+	case *ast.IndexListExpr:
+		fun = index.X
+	}
+
+	id, ok := tailIdent(fun)
+	if !ok {
+		return nil, codefmt.Errorf(p, expr, "cannot use %c as generic function", expr)
+	}
+
+	obj, ok := p.Pkg().TypesInfo.ObjectOf(id).(*types.Func)
+	if !ok || obj.Signature().TypeParams().Len() == 0 {
+		return nil, codefmt.Errorf(p, expr, "cannot use %c as generic function; use convgen.ImportFunc for non-generic function", expr)
+	}
 
+	fn, err := typeinfo.FuncOf[typeinfo.BothXY](obj)
+	if err != nil {
+		return nil, codefmt.Errorf(p, expr, "%s", err.Error())
+	}
+
+	if fn.HasOk() {
+		return nil, codefmt.Errorf(p, expr, "cannot use comma-ok function %c as generic function", expr) // unreachable
+	}
 	if hasErr && !fn.HasErr() {
 		return nil, codefmt.Errorf(p, expr, "function must return error") // unreachable
 	} else if !hasErr && fn.HasErr() {
 		return nil, codefmt.Errorf(p, expr, "function must not return error") // unreachable
 	}
+
+	if tparams := typeinfo.UninferableTypeParams(fn); len(tparams) != 0 {
+		return nil, codefmt.Errorf(p, expr, "cannot infer type parameter %s of %o from its input and output types", tparams[0].Obj().Name(), obj)
+	}
 	return fn, nil
 }
 
+// ParseSampleFuncs parses a sample expression and returns the exported
+// functions of the package of the sample which fit a conversion function. The
+// function is used for [convgen.ImportFuncsBySample].
+//
+// Only functions returning the output are imported. Functions taking an output
+// parameter, like func(In, *Out) error, are often not converters, such as
+// Decode(b []byte, v *T) error.
+func (p *Parser) ParseSampleFuncs(expr ast.Expr) ([]typeinfo.Func, error) {
+	expr = ast.Unparen(expr)
+	if p.IsNil(expr) {
+		return nil, codefmt.Errorf(p, expr, "cannot use nil as sample")
+	}
+
+	// A package-level object like conv.FormatID, or a value of a named type like
+	// conv.Money{}
+	var pkg *types.Package
+	if id, ok := tailIdent(expr); ok {
+		if obj := p.Pkg().TypesInfo.ObjectOf(id); obj != nil && obj.Pkg() != nil && obj.Parent() == obj.Pkg().Scope() {
+			pkg = obj.Pkg()
+		}
+	}
+	if pkg == nil {
+		t := types.Unalias(p.Pkg().TypesInfo.TypeOf(expr))
+		for {
+			ptr, ok := t.(*types.Pointer)
+			if !ok {
+				break
+			}
+			t = types.Unalias(ptr.Elem())
+		}
+		if named, ok := t.(*types.Named); ok {
+			pkg = named.Obj().Pkg()
+		}
+	}
+	if pkg == nil {
+		return nil, codefmt.Errorf(p, expr, "cannot find package of %c", expr)
+	}
+
+	var fns []typeinfo.Func
+	for _, name := range pkg.Scope().Names() {
+		obj, ok := pkg.Scope().Lookup(name).(*types.Func)
+		if !ok || !obj.Exported() {
+			continue
+		}
+
+		sig := obj.Signature()
+		if sig.TypeParams().Len() != 0 || sig.Variadic() {
+			continue
+		}
+
+		fn, err := typeinfo.FuncOf[typeinfo.BothXY](obj)
+		if err != nil || fn.HasOk() || fn.HasOut() {
+			// Comma-ok functions require convgen.ImportFuncOk, and functions
+			// with an output parameter require convgen.ImportFunc.
+			continue
+		}
+		if fn.HasErr() && sig.Results().Len() == 1 {
+			// func(In) error has no output.
+			continue
+		}
+		fns = append(fns, fn)
+	}
+
+	if len(fns) == 0 {
+		return nil, codefmt.Errorf(p, expr, "no exported function of package %s fits conversion function", pkg.Path())
+	}
+	return fns, nil
+}
+
 // ParseErrWrap parses an error wrapper function expression. The function must
 // take an error as the only parameter and return an error. The function is used
 // for [convgen.ErrWrap].
@@ -197,15 +349,15 @@ func (p *Parser) IsDirective(call *ast.CallExpr, name string) bool {
 func (p *Parser) ConvgenGoFiles() []*ast.File {
 	var files []*ast.File
 	for _, file := range p.Pkg().Syntax {
-		if hasGoBuildConvgen(file) {
+		if HasGoBuildConvgen(file) {
 			files = append(files, file)
 		}
 	}
 	return files
 }
 
-// hasGoBuildConvgen checks if the file has a "//go:build convgen" constraint.
-func hasGoBuildConvgen(file *ast.File) bool {
+// HasGoBuildConvgen checks if the file has a "//go:build convgen" constraint.
+func HasGoBuildConvgen(file *ast.File) bool {
 	ok := false
 	for _, group := range file.Comments {
 		for _, comment := range group.List {
@@ -0,0 +1,33 @@
+package parse
+
+import "go/token"
+
+// Usage records which options took effect while building converters. An option
+// is identified by the position of its directive call. Options never recorded
+// are reported as ineffective.
+//
+// A nil Usage is valid and records nothing.
+type Usage struct {
+	used map[token.Pos]bool
+}
+
+// NewUsage creates an empty [Usage].
+func NewUsage() *Usage {
+	return &Usage{used: make(map[token.Pos]bool)}
+}
+
+// Use marks the option at pos as effective.
+func (u *Usage) Use(pos token.Pos) {
+	if u == nil || !pos.IsValid() {
+		return
+	}
+	u.used[pos] = true
+}
+
+// Used reports whether the option at pos took effect.
+func (u *Usage) Used(pos token.Pos) bool {
+	if u == nil {
+		return false
+	}
+	return u.used[pos]
+}
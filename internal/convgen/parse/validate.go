@@ -43,7 +43,7 @@ func (p *Parser) validateConstraint(file *ast.File) error {
 	}
 
 	// Check for "//go:build convgen" constraint
-	if hasGoBuildConvgen(file) {
+	if HasGoBuildConvgen(file) {
 		return nil // Constraint satisfied
 	}
 
@@ -57,7 +57,7 @@ func (p *Parser) validateConstraint(file *ast.File) error {
 // directives, for example options, cannot be assigned. This is to prevent
 // remaining Convgen import after code generation.
 func (p *Parser) validateAssignedDirectives(file *ast.File) error {
-	if !hasGoBuildConvgen(file) {
+	if !HasGoBuildConvgen(file) {
 		return nil
 	}
 
@@ -102,10 +102,10 @@ func (p *Parser) validateAssignedDirectives(file *ast.File) error {
 
 // validateModuleUsages checks illegal references to modules.
 //
-// Modules are only allowed to be assigned to variables (except exported ones)
-// or used as arguments to Convgen directives. Any other usages are illegal,
-// because modules will be removed at code generation, and any remaining
-// references to modules will cause compilation errors.
+// Modules are only allowed to be assigned to variables or used as arguments to
+// Convgen directives. Any other usages are illegal, because modules will be
+// removed at code generation, and any remaining references to modules will
+// cause compilation errors.
 func (p *Parser) validateModuleUsages(mods map[token.Pos]*Module) error {
 	var errs error
 	blanks := p.findBlankValues()
@@ -142,14 +142,10 @@ func (p *Parser) validateModuleUsages(mods map[token.Pos]*Module) error {
 				return false
 			}
 
-			if id.IsExported() {
-				err := codefmt.Errorf(p, id, "cannot export module %q; removed at code generation", mod.Name)
-				errs = errors.Join(errs, err)
-				return false
-			}
-
 			if id.Pos() == obj.Pos() {
-				// This is the module identifier declaration. That's fine.
+				// This is the module identifier declaration. That's fine. An
+				// exported module may be imported by convgen.ImportModule in
+				// Convgen files of other packages, which are removed as well.
 				return false
 			}
 
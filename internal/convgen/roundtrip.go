@@ -0,0 +1,377 @@
+package convgeninternal
+
+import (
+	"bytes"
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sublee/convgen/internal/codefmt"
+	"github.com/sublee/convgen/internal/convgen/assign"
+)
+
+// roundTripPair is a pair of explicit struct converters which convert types to
+// each other, like EncodeUser from User to UserView and DecodeUser from
+// UserView to User.
+type roundTripPair struct {
+	enc, dec assign.Conv
+}
+
+// roundTripPairs returns the pairs of explicit struct converters in the order
+// of the encoders. A converter earlier in the source code is the encoder.
+func (cg *Convgen) roundTripPairs() []roundTripPair {
+	var pairs []roundTripPair
+	convs := cg.sortedConvs()
+	for i, enc := range convs {
+		if !cg.injs[enc.Pos()].Struct {
+			continue
+		}
+		for _, dec := range convs[i+1:] {
+			encInj, decInj := cg.injs[enc.Pos()], cg.injs[dec.Pos()]
+			if decInj.Struct &&
+				types.Identical(encInj.X().Type(), decInj.Y().Type()) &&
+				types.Identical(encInj.Y().Type(), decInj.X().Type()) {
+				pairs = append(pairs, roundTripPair{enc, dec})
+			}
+		}
+	}
+	return pairs
+}
+
+// GenerateRoundTripTests generates a test file with round-trip tests and fuzz
+// targets for each pair of explicit struct converters which convert types to
+// each other. It must be called after [Convgen.Generate] or
+// [Convgen.GenerateFiles] to avoid name conflicts with the generated
+// converters. It returns nil if there is no such pair.
+func (cg *Convgen) GenerateRoundTripTests() []byte {
+	var buf bytes.Buffer
+	w := cg.w.ForFile(&buf)
+	if !cg.writeRoundTripTests(w) {
+		return nil
+	}
+	return cg.frameCode(w, &buf)
+}
+
+// writeRoundTripTests writes round-trip tests like [Convgen.GenerateRoundTripTests].
+// It reports whether it wrote any test.
+//
+// A test fills the input of the encoder with random values by reflection,
+// converts it by the encoder and then by the decoder, and compares the result
+// with the input. Fields which the converters do not assign, such as fields
+// skipped by convgen.MatchSkip, are cleared in the input before the
+// comparison. Enum types converted by explicit enum converters are filled
+// with their matched members only. Inputs which the encoder fails to convert
+// are skipped, and a test is skipped if no input is convertible.
+func (cg *Convgen) writeRoundTripTests(w *codefmt.Writer) bool {
+	pairs := cg.roundTripPairs()
+	if len(pairs) == 0 {
+		return false
+	}
+
+	reflectPkg := w.Import("reflect", "reflect")
+	randPkg := w.Import("math/rand/v2", "rand")
+	strconvPkg := w.Import("strconv", "strconv")
+	testingPkg := w.Import("testing", "testing")
+
+	fill := cg.ns.Name("convgenFill")
+	clearFn := cg.ns.Name("convgenClear")
+	enums := cg.ns.Name("convgenEnums")
+
+	for _, pair := range pairs {
+		encInj, decInj := cg.injs[pair.enc.Pos()], cg.injs[pair.dec.Pos()]
+		base := encInj.Name() + "_" + decInj.Name()
+		test := cg.ns.Name("TestConvgenRoundTrip_" + base)
+		fuzz := cg.ns.Name("FuzzConvgenRoundTrip_" + base)
+		run := cg.ns.Name("convgenRoundTrip_" + base)
+
+		w.Printf("// %s tests %s(%s(x)) == x with random inputs.\n", test, decInj.Name(), encInj.Name())
+		w.Printf("func %s(t *%s.T) {\n", test, testingPkg)
+		w.Printf("converted := 0\n")
+		w.Printf("for seed := range uint64(100) {\n")
+		w.Printf("if %s(t, seed) {\n", run)
+		w.Printf("converted++\n")
+		w.Printf("}\n")
+		w.Printf("}\n")
+		w.Printf("if converted == 0 {\n")
+		w.Printf("t.Skip(\"no random input is convertible by %s\")\n", encInj.Name())
+		w.Printf("}\n")
+		w.Printf("}\n\n")
+
+		w.Printf("func %s(f *%s.F) {\n", fuzz, testingPkg)
+		w.Printf("f.Add(uint64(0))\n")
+		w.Printf("f.Fuzz(func(t *%s.T, seed uint64) {\n", testingPkg)
+		w.Printf("if !%s(t, seed) {\n", run)
+		w.Printf("t.Skip(\"the random input is not convertible by %s\")\n", encInj.Name())
+		w.Printf("}\n")
+		w.Printf("})\n")
+		w.Printf("}\n\n")
+
+		w.Printf("// %s reports whether %s converts the random input of the seed.\n", run, encInj.Name())
+		w.Printf("func %s(t *%s.T, seed uint64) bool {\n", run, testingPkg)
+		w.Printf("var in %t\n", encInj.X().Type())
+		w.Printf("%s(%s.ValueOf(&in).Elem(), %s.New(%s.NewPCG(seed, 0)), 0)\n", fill, reflectPkg, randPkg, randPkg)
+		if encInj.HasErr() {
+			w.Printf("mid, err := %s(in)\n", encInj.Name())
+			w.Printf("if err != nil {\n")
+			w.Printf("return false\n")
+			w.Printf("}\n")
+		} else {
+			w.Printf("mid := %s(in)\n", encInj.Name())
+		}
+		if decInj.HasErr() {
+			w.Printf("out, err := %s(mid)\n", decInj.Name())
+			w.Printf("if err != nil {\n")
+			w.Printf("t.Fatalf(\"seed %%d: %s: %%v\", seed, err)\n", decInj.Name())
+			w.Printf("}\n")
+		} else {
+			w.Printf("out := %s(mid)\n", decInj.Name())
+		}
+
+		w.Printf("%s(%s.ValueOf(&in).Elem(), map[%s.Type][]string{\n", clearFn, reflectPkg, reflectPkg)
+		for _, f := range cg.unassignedFields(pair) {
+			w.Printf("%s.TypeFor[%t](): {%s},\n", reflectPkg, f.owner, f.names)
+		}
+		w.Printf("})\n")
+
+		w.Printf("if !%s.DeepEqual(out, in) {\n", reflectPkg)
+		w.Printf("t.Errorf(\"seed %%d: %s(%s(x)) != x\\nx:   %%+v\\ngot: %%+v\", seed, in, out)\n", decInj.Name(), encInj.Name())
+		w.Printf("}\n")
+		w.Printf("return true\n")
+		w.Printf("}\n\n")
+	}
+
+	// Members of enum types
+	w.Printf("var %s = map[%s.Type][]any{\n", enums, reflectPkg)
+	for _, e := range cg.enumMembers() {
+		w.Printf("%s.TypeFor[%t](): {", reflectPkg, e.typ)
+		for i, con := range e.members {
+			if i != 0 {
+				w.Printf(", ")
+			}
+			w.Printf("%o", con)
+		}
+		w.Printf("},\n")
+	}
+	w.Printf("}\n\n")
+
+	w.Printf("%s", strings.NewReplacer(
+		"$fill", fill,
+		"$clear", clearFn,
+		"$enums", enums,
+		"$reflect", reflectPkg,
+		"$rand", randPkg,
+		"$strconv", strconvPkg,
+	).Replace(roundTripHelpers))
+	return true
+}
+
+// roundTripHelpers is the code of the helper functions of round-trip tests.
+// The $-prefixed placeholders are replaced with the names of the helpers and
+// the imported packages.
+const roundTripHelpers = `// $fill fills v with random values. Pointers, slices, and maps are left nil
+// beyond the depth limit to stop at recursive types.
+func $fill(v $reflect.Value, r *$rand.Rand, depth int) {
+	if members, ok := $enums[v.Type()]; ok {
+		v.Set($reflect.ValueOf(members[r.IntN(len(members))]).Convert(v.Type()))
+		return
+	}
+	switch v.Kind() {
+	case $reflect.Bool:
+		v.SetBool(r.IntN(2) == 1)
+	case $reflect.Int, $reflect.Int8, $reflect.Int16, $reflect.Int32, $reflect.Int64:
+		v.SetInt(int64(r.IntN(100)))
+	case $reflect.Uint, $reflect.Uint8, $reflect.Uint16, $reflect.Uint32, $reflect.Uint64, $reflect.Uintptr:
+		v.SetUint(uint64(r.IntN(100)))
+	case $reflect.Float32, $reflect.Float64:
+		v.SetFloat(float64(r.IntN(400)) / 4)
+	case $reflect.String:
+		v.SetString($strconv.Itoa(r.IntN(100)))
+	case $reflect.Pointer:
+		if depth < 3 && r.IntN(4) != 0 {
+			p := $reflect.New(v.Type().Elem())
+			$fill(p.Elem(), r, depth+1)
+			v.Set(p)
+		}
+	case $reflect.Slice:
+		if depth < 3 {
+			n := 1 + r.IntN(2)
+			s := $reflect.MakeSlice(v.Type(), n, n)
+			for i := range n {
+				$fill(s.Index(i), r, depth+1)
+			}
+			v.Set(s)
+		}
+	case $reflect.Array:
+		for i := range v.Len() {
+			$fill(v.Index(i), r, depth+1)
+		}
+	case $reflect.Map:
+		if depth < 3 {
+			m := $reflect.MakeMap(v.Type())
+			for range 1 + r.IntN(2) {
+				key := $reflect.New(v.Type().Key()).Elem()
+				elem := $reflect.New(v.Type().Elem()).Elem()
+				$fill(key, r, depth+1)
+				$fill(elem, r, depth+1)
+				m.SetMapIndex(key, elem)
+			}
+			v.Set(m)
+		}
+	case $reflect.Struct:
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				$fill(v.Field(i), r, depth)
+			}
+		}
+	}
+}
+
+// $clear zeroes the fields which converters do not assign. fields maps struct
+// types to the names of such fields.
+func $clear(v $reflect.Value, fields map[$reflect.Type][]string) {
+	switch v.Kind() {
+	case $reflect.Pointer:
+		if !v.IsNil() {
+			$clear(v.Elem(), fields)
+		}
+	case $reflect.Slice, $reflect.Array:
+		for i := range v.Len() {
+			$clear(v.Index(i), fields)
+		}
+	case $reflect.Map:
+		for _, key := range v.MapKeys() {
+			elem := $reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+			$clear(elem, fields)
+			v.SetMapIndex(key, elem)
+		}
+	case $reflect.Struct:
+		for _, name := range fields[v.Type()] {
+			if f := v.FieldByName(name); f.CanSet() {
+				f.SetZero()
+			}
+		}
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				$clear(v.Field(i), fields)
+			}
+		}
+	}
+}
+`
+
+// roundTripFields is the names of unassigned fields of a struct type.
+type roundTripFields struct {
+	owner types.Type
+	names string // quoted and comma-separated
+}
+
+// unassignedFields returns the fields which are lost in the round trip: the
+// fields of X not assigned by the encoder and the fields of Y not assigned by
+// the decoder, including the subconverters they depend on.
+func (cg *Convgen) unassignedFields(pair roundTripPair) []roundTripFields {
+	var fields []assign.Field
+	for _, conv := range cg.reachableConvs(pair.enc) {
+		xs, _ := assign.UnassignedFields(conv)
+		fields = append(fields, xs...)
+	}
+	for _, conv := range cg.reachableConvs(pair.dec) {
+		_, ys := assign.UnassignedFields(conv)
+		fields = append(fields, ys...)
+	}
+
+	var out []roundTripFields
+	var names [][]string
+	for _, f := range fields {
+		if named, ok := f.Owner.(*types.Named); ok && !named.Obj().Exported() && named.Obj().Pkg() != cg.p.Pkg().Types {
+			// Not referable in the test file
+			continue
+		}
+
+		i := slices.IndexFunc(out, func(o roundTripFields) bool { return types.Identical(o.owner, f.Owner) })
+		if i == -1 {
+			i = len(out)
+			out = append(out, roundTripFields{owner: f.Owner})
+			names = append(names, nil)
+		}
+		if !slices.Contains(names[i], f.Name) {
+			names[i] = append(names[i], f.Name)
+		}
+	}
+	for i := range out {
+		for j, name := range names[i] {
+			if j != 0 {
+				out[i].names += ", "
+			}
+			out[i].names += strconv.Quote(name)
+		}
+	}
+	return out
+}
+
+// reachableConvs returns the converter and the explicit and implicit
+// converters which it calls directly or indirectly.
+func (cg *Convgen) reachableConvs(conv assign.Conv) []assign.Conv {
+	byName := make(map[string]assign.Conv)
+	for _, conv := range cg.sortedConvs() {
+		byName[cg.injs[conv.Pos()].Name()] = conv
+	}
+	for _, mod := range cg.sortedMods() {
+		for _, conv := range cg.subconvs[mod] {
+			byName[assign.Describe(conv).Name] = conv
+		}
+	}
+
+	convs := []assign.Conv{conv}
+	for i := 0; i < len(convs); i++ {
+		for _, call := range assign.Calls(convs[i]) {
+			name := call.Callee.Name
+			if call.Callee.Func != nil && name == "" {
+				// A subconverter created by another converter is called as a
+				// function.
+				name = call.Callee.Func.Name()
+			}
+			if c, ok := byName[name]; ok && !slices.Contains(convs, c) {
+				convs = append(convs, c)
+			}
+		}
+	}
+	return convs
+}
+
+// roundTripEnum is the matched members of an enum type.
+type roundTripEnum struct {
+	typ     types.Type
+	members []*types.Const
+}
+
+// enumMembers returns the members of enum types matched by the explicit enum
+// converters.
+func (cg *Convgen) enumMembers() []roundTripEnum {
+	var enums []roundTripEnum
+	add := func(members []*types.Const) {
+		if len(members) == 0 {
+			return
+		}
+		typ := members[0].Type()
+		i := slices.IndexFunc(enums, func(e roundTripEnum) bool { return types.Identical(e.typ, typ) })
+		if i == -1 {
+			i = len(enums)
+			enums = append(enums, roundTripEnum{typ: typ})
+		}
+		for _, con := range members {
+			if con.Exported() || con.Pkg() == cg.p.Pkg().Types {
+				if !slices.Contains(enums[i].members, con) {
+					enums[i].members = append(enums[i].members, con)
+				}
+			}
+		}
+	}
+	for _, conv := range cg.sortedConvs() {
+		xs, ys := assign.EnumMembers(conv)
+		add(xs)
+		add(ys)
+	}
+	return slices.DeleteFunc(enums, func(e roundTripEnum) bool { return len(e.members) == 0 })
+}
@@ -0,0 +1,143 @@
+package convgeninternal
+
+import (
+	"errors"
+	"go/ast"
+	"go/token"
+	"maps"
+	"slices"
+
+	"github.com/sublee/convgen/internal/codefmt"
+	"github.com/sublee/convgen/internal/convgen/parse"
+)
+
+// warnIneffective reports options which took no effect while building
+// converters. It must be called after [Convgen.Build] succeeds because the
+// usage of options is recorded during the build.
+func (cg *Convgen) warnIneffective() error {
+	usage := cg.p.Usage()
+	calls := cg.directiveCalls()
+
+	// Collect modules in use and the configs applied to converters. Options in
+	// a config which is never applied are reported by the qualifier instead.
+	mods := make(map[*parse.Module]bool)
+	var cfgs []parse.Config
+	for _, inj := range cg.injs {
+		mods[inj.Module] = true
+		cfgs = append(cfgs, inj.Config)
+	}
+	for mod, subconvs := range cg.subconvs {
+		if len(subconvs) != 0 {
+			cfgs = append(cfgs, mod.Config.ForkForStruct())
+		}
+	}
+
+	warns := make(map[token.Pos]error)
+	warn := func(pos token.Pos, format string, args ...any) {
+		if _, ok := warns[pos]; !ok {
+			warns[pos] = codefmt.Warnf(cg.p, codefmt.Pos(pos), format, args...)
+		}
+	}
+
+	for _, cfg := range cfgs {
+		for _, at := range slices.Concat(cfg.RenamersAtX, cfg.RenamersAtY) {
+			if usage.Used(at) {
+				continue
+			}
+			if call, ok := calls[at]; ok {
+				warn(at, "ineffective %c: no name is renamed", call.Fun)
+			}
+		}
+
+		if cfg.DiscoverGettersEnabled && cfg.DiscoverGettersAt.IsValid() && !usage.Used(cfg.DiscoverGettersAt) {
+			warn(cfg.DiscoverGettersAt, "ineffective convgen.DiscoverGetters: no method matches prefix %q and suffix %q",
+				cfg.DiscoverGettersPrefix, cfg.DiscoverGettersSuffix)
+		}
+		if cfg.DiscoverSettersEnabled && cfg.DiscoverSettersAt.IsValid() && !usage.Used(cfg.DiscoverSettersAt) {
+			warn(cfg.DiscoverSettersAt, "ineffective convgen.DiscoverSetters: no method matches prefix %q and suffix %q",
+				cfg.DiscoverSettersPrefix, cfg.DiscoverSettersSuffix)
+		}
+	}
+
+	for _, inj := range cg.injs {
+		for fn := range inj.Funcs.Range() {
+			if usage.Used(fn.Pos()) {
+				continue
+			}
+			if call, ok := calls[fn.Pos()]; ok && len(call.Args) != 0 {
+				warn(fn.Pos(), "ineffective %c: %c is never called", call.Fun, call.Args[0])
+			}
+		}
+	}
+
+	for mod := range mods {
+		for i, fn := range mod.Config.Funcs {
+			if usage.Used(fn.Pos()) {
+				continue
+			}
+			call, ok := mod.Config.FuncExprs[i].(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				continue
+			}
+			switch {
+			case cg.p.IsDirective(call, "ImportModule"):
+				warn(fn.Pos(), "ineffective %c: no converter of %c is ever called", call.Fun, call.Args[0])
+			case cg.p.IsDirective(call, "ImportFuncsBySample"):
+				warn(fn.Pos(), "ineffective %c: no function of the package of %c is ever called", call.Fun, call.Args[0])
+			default:
+				warn(fn.Pos(), "ineffective %c: %c is never called", call.Fun, call.Args[0])
+			}
+		}
+
+		var hasStruct, hasUnion, hasEnum bool
+		for _, inj := range cg.injs {
+			if inj.Module != mod {
+				continue
+			}
+			hasStruct = hasStruct || inj.Struct
+			hasUnion = hasUnion || inj.Union
+			hasEnum = hasEnum || inj.Enum
+		}
+		hasStruct = hasStruct || len(cg.subconvs[mod]) != 0
+
+		if !hasStruct {
+			for _, at := range mod.Config.ForStructAt {
+				warn(at, "ineffective convgen.ForStruct: no struct converter in the module")
+			}
+		}
+		if !hasUnion {
+			for _, at := range mod.Config.ForUnionAt {
+				warn(at, "ineffective convgen.ForUnion: no union converter in the module")
+			}
+		}
+		if !hasEnum {
+			for _, at := range mod.Config.ForEnumAt {
+				warn(at, "ineffective convgen.ForEnum: no enum converter in the module")
+			}
+		}
+	}
+
+	var errs error
+	for _, pos := range slices.Sorted(maps.Keys(warns)) {
+		errs = errors.Join(errs, warns[pos])
+	}
+	return errs
+}
+
+// directiveCalls indexes call expressions in Convgen files by their positions.
+// If multiple calls share a position, like a method call chain, the outermost
+// one is indexed.
+func (cg *Convgen) directiveCalls() map[token.Pos]*ast.CallExpr {
+	calls := make(map[token.Pos]*ast.CallExpr)
+	for _, file := range cg.p.ConvgenGoFiles() {
+		ast.Inspect(file, func(n ast.Node) bool {
+			if call, ok := n.(*ast.CallExpr); ok {
+				if _, ok := calls[call.Pos()]; !ok {
+					calls[call.Pos()] = call
+				}
+			}
+			return true
+		})
+	}
+	return calls
+}
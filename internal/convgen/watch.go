@@ -0,0 +1,229 @@
+package convgeninternal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Watch runs Convgen like [Main] and then keeps running it whenever a file that
+// the target packages depend on changes. Files are polled at the given
+// interval. Only the packages affected by the changed files are reloaded and
+// rebuilt, so report receives the results of those packages only.
+//
+// report is called with the result of every run, including the first one.
+// Watch blocks until ctx is done and then returns the context error.
+func Watch(ctx context.Context, opts Options, interval time.Duration, report func(Result, error)) error {
+	w := &watcher{
+		opts:   opts,
+		files:  make(map[string][]string),
+		dirs:   make(map[string][]string),
+		stamps: make(map[string]stamp),
+	}
+
+	start := time.Now()
+	pkgs, err := load(ctx, opts, opts.Patterns)
+	if pkgs == nil && err != nil {
+		// Nothing to watch
+		return err
+	}
+	w.track(pkgs, start)
+	w.run(pkgs, err, report)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		affected := w.poll()
+		if len(affected) == 0 {
+			continue
+		}
+
+		start := time.Now()
+		pkgs, err := load(ctx, opts, affected)
+		w.track(pkgs, start)
+		w.run(pkgs, err, report)
+	}
+}
+
+// watcher tracks the files which the target packages depend on.
+type watcher struct {
+	opts Options
+
+	// files maps the path of a target package to the files of the package and
+	// its dependencies which may be edited.
+	files map[string][]string
+
+	// dirs maps the path of a target package to the Go files in its directory
+	// to detect added or removed files.
+	dirs map[string][]string
+
+	// stamps holds the last seen stamp of each file.
+	stamps map[string]stamp
+}
+
+// stamp identifies a version of a file.
+type stamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// statStamp returns the current stamp of the file at path. A removed file has
+// a zero stamp.
+func statStamp(path string) stamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return stamp{}
+	}
+	return stamp{info.ModTime(), info.Size()}
+}
+
+// run generates code for the loaded packages and reports the result. If loading
+// failed, the error is reported instead.
+func (w *watcher) run(pkgs []*packages.Package, loadErr error, report func(Result, error)) {
+	if loadErr != nil {
+		report(Result{}, loadErr)
+		return
+	}
+	report(generate(pkgs, w.opts))
+}
+
+// track records the files of the loaded packages and their dependencies, which
+// [load] loads as a package graph. start is when the loading started.
+//
+// The stamps must be as old as the loaded sources. The stamps of known files
+// were recorded by [watcher.poll] before loading, so they are kept. A new file
+// modified since start may have been loaded before the modification, so it
+// gets a zero stamp to be regenerated at the next poll.
+func (w *watcher) track(pkgs []*packages.Package, start time.Time) {
+	for _, pkg := range pkgs {
+		path, ok := w.pkgPath(pkg)
+		if !ok {
+			continue
+		}
+
+		files := w.depFiles(pkg)
+		for _, file := range files {
+			if _, ok := w.stamps[file]; ok {
+				continue
+			}
+			if now := statStamp(file); now.modTime.Before(start) {
+				w.stamps[file] = now
+			} else {
+				w.stamps[file] = stamp{}
+			}
+		}
+		w.files[path] = files
+
+		if _, ok := w.dirs[path]; !ok && len(pkg.GoFiles) != 0 {
+			w.dirs[path] = w.listGoFiles(filepath.Dir(pkg.GoFiles[0]))
+		}
+	}
+}
+
+// poll returns the paths of the target packages affected by changed files
+// since the last poll.
+func (w *watcher) poll() []string {
+	changed := make(map[string]bool)
+	for file, old := range w.stamps {
+		if now := statStamp(file); now != old {
+			w.stamps[file] = now
+			changed[file] = true
+		}
+	}
+
+	var affected []string
+	for path, files := range w.files {
+		dirty := slices.ContainsFunc(files, func(file string) bool { return changed[file] })
+
+		// Added or removed files change the package too.
+		if dir := w.dirs[path]; len(dir) != 0 {
+			if now := w.listGoFiles(filepath.Dir(dir[0])); !slices.Equal(now, dir) {
+				w.dirs[path] = now
+				dirty = true
+			}
+		}
+
+		if dirty {
+			affected = append(affected, path)
+		}
+	}
+
+	slices.Sort(affected)
+	return affected
+}
+
+// pkgPath returns the import path to reload the package. Test variants share
+// the path of the package under test. Synthesized test main packages are not
+// tracked.
+func (w *watcher) pkgPath(pkg *packages.Package) (string, bool) {
+	if strings.HasSuffix(pkg.ID, ".test") {
+		return "", false
+	}
+	return strings.TrimSuffix(pkg.PkgPath, "_test"), true
+}
+
+// depFiles returns the Go files of the package and its dependencies except
+// the standard library and immutable modules from the module cache.
+func (w *watcher) depFiles(root *packages.Package) []string {
+	var files []string
+	seen := make(map[*packages.Package]bool)
+
+	var visit func(pkg *packages.Package)
+	visit = func(pkg *packages.Package) {
+		if seen[pkg] || !editable(pkg) {
+			return
+		}
+		seen[pkg] = true
+
+		files = append(files, pkg.GoFiles...)
+		for _, imp := range pkg.Imports {
+			visit(imp)
+		}
+	}
+	visit(root)
+
+	slices.Sort(files)
+	return slices.Compact(files)
+}
+
+// editable reports whether the package could be edited by the user.
+func editable(pkg *packages.Package) bool {
+	if mod := pkg.Module; mod != nil {
+		// Dependencies in the module cache never change. But a dependency
+		// replaced with a local directory may change.
+		return mod.Main || (mod.Replace != nil && mod.Replace.Version == "")
+	}
+
+	// Outside of module mode, the standard library has no dots in the first
+	// element of the path.
+	first, _, _ := strings.Cut(pkg.PkgPath, "/")
+	return strings.Contains(first, ".")
+}
+
+// listGoFiles lists the Go files in dir except the output files.
+func (w *watcher) listGoFiles(dir string) []string {
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	return slices.DeleteFunc(matches, func(path string) bool {
+		name := filepath.Base(path)
+		if name == w.opts.OutFile {
+			return true
+		}
+		if w.opts.Split {
+			return name == strings.TrimSuffix(w.opts.OutFile, ".go")+"_test.go" ||
+				strings.HasSuffix(name, "_gen.go") || strings.HasSuffix(name, "_gen_test.go")
+		}
+		return false
+	})
+}
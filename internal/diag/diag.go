@@ -0,0 +1,123 @@
+// Package diag converts errors reported by Convgen into structured diagnostics
+// for machine-readable outputs, such as JSON and SARIF.
+package diag
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/sublee/convgen/internal/codefmt"
+)
+
+// Diagnostic is a structured form of an error, a warning, or an informational
+// message.
+type Diagnostic struct {
+	File      string  `json:"file,omitempty"`
+	Line      int     `json:"line,omitempty"`
+	Column    int     `json:"column,omitempty"`
+	EndLine   int     `json:"endLine,omitempty"`
+	EndColumn int     `json:"endColumn,omitempty"`
+	Severity  string  `json:"severity"`
+	Converter string  `json:"converter,omitempty"`
+	Message   string  `json:"message"`
+	Matches   []Match `json:"matches,omitempty"`
+}
+
+// Match is a row of the match table of a diagnostic for a match failure.
+type Match struct {
+	Status  string `json:"status"` // "ok" or "FAIL"
+	X       string `json:"x"`
+	Y       string `json:"y"`
+	Skipped bool   `json:"skipped,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// Collect unrolls joined errors into diagnostics in order. File paths are
+// relative to wd if possible.
+func Collect(wd string, err error) []Diagnostic {
+	var diags []Diagnostic
+	collect(wd, err, &diags)
+	return diags
+}
+
+func collect(wd string, err error, diags *[]Diagnostic) {
+	switch err := err.(type) {
+	case nil:
+		return
+
+	case *codefmt.CodeError:
+		d := Diagnostic{
+			Severity:  err.Severity().String(),
+			Converter: err.Converter(),
+			Message:   err.Unwrap().Error(),
+		}
+		if pos := err.Position(); pos.IsValid() {
+			d.File = relPath(wd, pos.Filename)
+			d.Line, d.Column = pos.Line, pos.Column
+		}
+		if end := err.EndPosition(); end.IsValid() {
+			d.EndLine, d.EndColumn = end.Line, end.Column
+		}
+		for _, row := range err.MatchRows() {
+			m := Match{Status: "ok", X: row.X, Y: row.Y, Skipped: row.Skipped, Reason: row.Reason}
+			if !row.OK {
+				m.Status = "FAIL"
+			}
+			d.Matches = append(d.Matches, m)
+		}
+		*diags = append(*diags, d)
+
+	case packages.Error:
+		d := Diagnostic{Severity: codefmt.SeverityError.String(), Message: err.Msg}
+		d.File, d.Line, d.Column = parsePos(err.Pos)
+		d.File = relPath(wd, d.File)
+		*diags = append(*diags, d)
+
+	case interface{ Unwrap() []error }:
+		for _, err := range err.Unwrap() {
+			collect(wd, err, diags)
+		}
+
+	default:
+		*diags = append(*diags, Diagnostic{Severity: codefmt.SeverityError.String(), Message: err.Error()})
+	}
+}
+
+// parsePos parses a position in the form of "file:line:col" or "file:line".
+func parsePos(pos string) (file string, line, col int) {
+	file = pos
+	var nums []int
+	for range 2 {
+		i := strings.LastIndexByte(file, ':')
+		if i < 0 {
+			break
+		}
+		n, err := strconv.Atoi(file[i+1:])
+		if err != nil {
+			break
+		}
+		nums = append([]int{n}, nums...)
+		file = file[:i]
+	}
+
+	switch len(nums) {
+	case 1:
+		line = nums[0]
+	case 2:
+		line, col = nums[0], nums[1]
+	}
+	return file, line, col
+}
+
+func relPath(wd, path string) string {
+	if path == "" || !filepath.IsAbs(path) {
+		return path
+	}
+	if rel, err := filepath.Rel(wd, path); err == nil {
+		return rel
+	}
+	return path
+}
@@ -0,0 +1,81 @@
+package diag_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/sublee/convgen/internal/codefmt"
+	"github.com/sublee/convgen/internal/diag"
+)
+
+type pkger struct{ fset *token.FileSet }
+
+func (p pkger) Pkg() *packages.Package { return &packages.Package{Fset: p.fset} }
+
+func TestCollect(t *testing.T) {
+	fset := token.NewFileSet()
+	file := fset.AddFile("/src/main.go", -1, 100)
+	file.SetLines([]int{0, 10, 20})
+	p := pkger{fset}
+
+	err := codefmt.Errorf(p, codefmt.Pos(file.Pos(12)), "invalid match")
+	err = codefmt.WithMatchRows(err, codefmt.MatchRow{OK: false, X: "A", Y: "?", Reason: "missing"})
+	err = codefmt.WithConverter(err, "Convert")
+	warn := codefmt.Warnf(p, codefmt.Pos(file.Pos(1)), "ineffective option")
+	loadErr := packages.Error{Pos: "/src/other.go:3:4", Msg: "syntax error"}
+
+	diags := diag.Collect("/src", errors.Join(err, errors.Join(warn, loadErr), errors.New("plain")))
+	require.Len(t, diags, 4)
+
+	assert.Equal(t, diag.Diagnostic{
+		File:      "main.go",
+		Line:      2,
+		Column:    3,
+		Severity:  "error",
+		Converter: "Convert",
+		Message:   "invalid match",
+		Matches:   []diag.Match{{Status: "FAIL", X: "A", Y: "?", Reason: "missing"}},
+	}, diags[0])
+	assert.Equal(t, diag.Diagnostic{File: "main.go", Line: 1, Column: 2, Severity: "warning", Message: "ineffective option"}, diags[1])
+	assert.Equal(t, diag.Diagnostic{File: "other.go", Line: 3, Column: 4, Severity: "error", Message: "syntax error"}, diags[2])
+	assert.Equal(t, diag.Diagnostic{Severity: "error", Message: "plain"}, diags[3])
+}
+
+func TestWriteSARIF(t *testing.T) {
+	diags := []diag.Diagnostic{{File: "main.go", Line: 1, Column: 2, Severity: "warning", Message: "ineffective option"}}
+
+	var buf bytes.Buffer
+	require.NoError(t, diag.WriteSARIF(&buf, diags, "v1.0.0"))
+
+	var log struct {
+		Version string
+		Runs    []struct {
+			Results []struct {
+				Level     string
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct{ URI string }
+						Region           struct{ StartLine, StartColumn int }
+					}
+				}
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	require.Len(t, log.Runs[0].Results, 1)
+
+	result := log.Runs[0].Results[0]
+	assert.Equal(t, "warning", result.Level)
+	assert.Equal(t, "main.go", result.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, 1, result.Locations[0].PhysicalLocation.Region.StartLine)
+	assert.Equal(t, 2, result.Locations[0].PhysicalLocation.Region.StartColumn)
+}
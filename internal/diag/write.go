@@ -0,0 +1,128 @@
+package diag
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+
+	"github.com/sublee/convgen/internal/codefmt"
+)
+
+// WriteJSON writes diagnostics as a JSON array.
+func WriteJSON(w io.Writer, diags []Diagnostic) error {
+	if diags == nil {
+		diags = []Diagnostic{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(diags)
+}
+
+// WriteSARIF writes diagnostics as a SARIF 2.1.0 log. version is the version
+// of the Convgen tool.
+func WriteSARIF(w io.Writer, diags []Diagnostic, version string) error {
+	results := make([]sarifResult, 0, len(diags))
+	for _, d := range diags {
+		r := sarifResult{
+			RuleID:  "convgen",
+			Level:   sarifLevel(d.Severity),
+			Message: sarifMessage{Text: d.Message},
+		}
+		if d.File != "" {
+			r.Locations = []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(d.File)},
+					Region: sarifRegion{
+						StartLine:   d.Line,
+						StartColumn: d.Column,
+						EndLine:     d.EndLine,
+						EndColumn:   d.EndColumn,
+					},
+				},
+			}}
+		}
+		if d.Converter != "" || len(d.Matches) != 0 {
+			r.Properties = &sarifProperties{Converter: d.Converter, Matches: d.Matches}
+		}
+		results = append(results, r)
+	}
+
+	log := sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "convgen",
+				InformationURI: "https://github.com/sublee/convgen",
+				Version:        version,
+			}},
+			Results: results,
+		}},
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(log)
+}
+
+// SARIF 2.1.0 objects. Only the properties Convgen fills are defined.
+type (
+	sarifLog struct {
+		Version string     `json:"version"`
+		Schema  string     `json:"$schema"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string `json:"name"`
+		InformationURI string `json:"informationUri"`
+		Version        string `json:"version,omitempty"`
+	}
+	sarifResult struct {
+		RuleID     string           `json:"ruleId"`
+		Level      string           `json:"level"`
+		Message    sarifMessage     `json:"message"`
+		Locations  []sarifLocation  `json:"locations,omitempty"`
+		Properties *sarifProperties `json:"properties,omitempty"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           sarifRegion           `json:"region"`
+	}
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+	sarifRegion struct {
+		StartLine   int `json:"startLine,omitempty"`
+		StartColumn int `json:"startColumn,omitempty"`
+		EndLine     int `json:"endLine,omitempty"`
+		EndColumn   int `json:"endColumn,omitempty"`
+	}
+	sarifProperties struct {
+		Converter string  `json:"converter,omitempty"`
+		Matches   []Match `json:"matches,omitempty"`
+	}
+)
+
+// sarifLevel returns the SARIF level of the severity. SARIF calls information
+// a "note".
+func sarifLevel(severity string) string {
+	if severity == codefmt.SeverityInfo.String() {
+		return "note"
+	}
+	return severity
+}
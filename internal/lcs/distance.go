@@ -0,0 +1,31 @@
+package lcs
+
+// Distance returns the Levenshtein edit distance between a and b, which is the
+// minimum number of single-byte insertions, deletions, or substitutions
+// required to change a into b.
+func Distance(a, b string) int {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+
+	// Keep only two rows of the dynamic programming table. The shorter string
+	// is used for the columns to save memory.
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,19 @@
+package lcs_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sublee/convgen/internal/lcs"
+)
+
+func TestDistance(t *testing.T) {
+	assert.Equal(t, 0, lcs.Distance("", ""))
+	assert.Equal(t, 0, lcs.Distance("same", "same"))
+	assert.Equal(t, 3, lcs.Distance("", "abc"))
+	assert.Equal(t, 3, lcs.Distance("abc", ""))
+	assert.Equal(t, 3, lcs.Distance("kitten", "sitting"))
+	assert.Equal(t, 1, lcs.Distance("Adress", "Address"))
+	assert.Equal(t, 1, lcs.Distance("Address", "Adress"))
+}
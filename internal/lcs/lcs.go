@@ -1,5 +1,5 @@
 // Package lcs provides functions for finding the longest common prefix and
-// suffix of a slice of strings.
+// suffix of a slice of strings, and for measuring how close two strings are.
 package lcs
 
 import (
@@ -23,6 +23,14 @@ type Func interface {
 	HasErr() bool
 	HasOut() bool
 
+	// HasOk reports whether the function reports success by a boolean as the
+	// last return value, like func(X) (Y, bool).
+	HasOk() bool
+
+	// TypeArgs returns the type arguments if the function is an instance of
+	// a generic function. See [Instantiate].
+	TypeArgs() []types.Type
+
 	// Position information
 	Pos() token.Pos
 	WithPos(token.Pos) Func
@@ -36,7 +44,12 @@ type function struct {
 	y      Type
 	hasErr bool
 	hasOut bool
+	hasOk  bool
 	pos    token.Pos
+
+	// targs is a pointer to keep function comparable because functions are
+	// used as map keys.
+	targs *[]types.Type
 }
 
 func (fn function) Object() types.Object { return fn.obj }
@@ -53,6 +66,14 @@ func (fn function) X() Type      { return fn.x }
 func (fn function) Y() Type      { return fn.y }
 func (fn function) HasErr() bool { return fn.hasErr }
 func (fn function) HasOut() bool { return fn.hasOut }
+func (fn function) HasOk() bool  { return fn.hasOk }
+
+func (fn function) TypeArgs() []types.Type {
+	if fn.targs == nil {
+		return nil
+	}
+	return *fn.targs
+}
 
 func (fn function) Pos() token.Pos {
 	if fn.pos == token.NoPos {
@@ -94,7 +115,7 @@ func NewFunc(pkg *types.Package, name string, x, y Type, hasErr, hasOut bool) Fu
 
 // WithPos returns a copy of the [Func] with the given position.
 func (fn function) WithPos(pos token.Pos) Func {
-	return function{fn.obj, fn.lit, fn.x, fn.y, fn.hasErr, fn.hasOut, pos}
+	return function{fn.obj, fn.lit, fn.x, fn.y, fn.hasErr, fn.hasOut, fn.hasOk, pos, fn.targs}
 }
 
 // Shape is a type constraint for function shapes. It is used in [FuncOf] and
@@ -106,6 +127,9 @@ func (fn function) WithPos(pos token.Pos) Func {
 //	OnlyX   | func(x)    | func(x) error       | -            | -
 //	OnlyY   | func() y   | func() (y, error)   | func(*y),    | func(*y) error
 //	BothXY  | func(x) y  | func(x) (y, error)  | func(x, *y)  | func(x, *y) error
+//
+// BothXY also accepts func(x) (y, bool) which reports success by a boolean.
+// See [Func.HasOk].
 type Shape interface {
 	NoXY | OnlyX | OnlyY | BothXY
 	needXY() (bool, bool)
@@ -185,6 +209,12 @@ func funcOfBothXY(f function, params, results *types.Tuple) (Func, error) {
 		f.x = TypeOf(params.At(0).Type())
 		f.y = TypeOf(results.At(0).Type())
 		return f, nil
+	case params.Len() == 1 && results.Len() == 2 && isTypeBool(results.At(1).Type()):
+		// func(X) (Y, bool)
+		f.x = TypeOf(params.At(0).Type())
+		f.y = TypeOf(results.At(0).Type())
+		f.hasOk = true
+		return f, nil
 	case params.Len() == 2 && results.Len() == 0 && !f.hasErr:
 		// func(X, *Y)
 		if ptr := TypeOf(params.At(1).Type()); ptr.IsPointer() {
@@ -202,7 +232,7 @@ func funcOfBothXY(f function, params, results *types.Tuple) (Func, error) {
 			return f, nil
 		}
 	}
-	return nil, fmt.Errorf("expected signature: [func(X) Y], [func(X) (Y, error)], [func(X, *Y) bool], [func(X, *Y) error]")
+	return nil, fmt.Errorf("expected signature: [func(X) Y], [func(X) (Y, error)], [func(X) (Y, bool)], [func(X, *Y)], [func(X, *Y) error]")
 }
 
 // FuncOf inspects the given function and returns a new [Func]. It returns an
@@ -250,9 +280,15 @@ func FuncLitOf[S Shape](pkg *packages.Package, lit *ast.FuncLit) (Func, error) {
 		y:      fn.Y(),
 		hasErr: fn.HasErr(),
 		hasOut: fn.HasOut(),
+		hasOk:  fn.HasOk(),
 	}, nil
 }
 
+// isTypeBool reports whether t is the built-in bool type.
+func isTypeBool(t types.Type) bool {
+	return t == types.Typ[types.Bool]
+}
+
 // isTypeError reports whether t is the built-in error type.
 func isTypeError(t types.Type) bool {
 	return t == types.Universe.Lookup("error").Type()
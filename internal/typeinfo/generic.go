@@ -0,0 +1,161 @@
+package typeinfo
+
+import (
+	"go/types"
+)
+
+// typeParams returns the type parameters of the function if it is a generic
+// function. Otherwise, it returns nil.
+func typeParams(fn Func) *types.TypeParamList {
+	obj, ok := fn.Object().(*types.Func)
+	if !ok || len(fn.TypeArgs()) != 0 {
+		return nil
+	}
+	return obj.Signature().TypeParams()
+}
+
+// IsGeneric reports whether the function is a generic function which is not
+// instantiated yet.
+func IsGeneric(fn Func) bool {
+	return typeParams(fn).Len() != 0
+}
+
+// UninferableTypeParams returns the type parameters of the generic function
+// which appear in neither its input nor its output. [Instantiate] cannot infer
+// them.
+func UninferableTypeParams(fn Func) []*types.TypeParam {
+	used := make(map[*types.TypeParam]bool)
+	collectTypeParams(fn.X().Type(), used)
+	collectTypeParams(fn.Y().Type(), used)
+
+	var tparams []*types.TypeParam
+	for tparam := range typeParams(fn).TypeParams() {
+		if !used[tparam] {
+			tparams = append(tparams, tparam)
+		}
+	}
+	return tparams
+}
+
+// collectTypeParams adds the type parameters appearing in t to used.
+func collectTypeParams(t types.Type, used map[*types.TypeParam]bool) {
+	switch t := types.Unalias(t).(type) {
+	case *types.TypeParam:
+		used[t] = true
+	case *types.Pointer:
+		collectTypeParams(t.Elem(), used)
+	case *types.Slice:
+		collectTypeParams(t.Elem(), used)
+	case *types.Array:
+		collectTypeParams(t.Elem(), used)
+	case *types.Chan:
+		collectTypeParams(t.Elem(), used)
+	case *types.Map:
+		collectTypeParams(t.Key(), used)
+		collectTypeParams(t.Elem(), used)
+	case *types.Named:
+		for targ := range t.TypeArgs().Types() {
+			collectTypeParams(targ, used)
+		}
+	}
+}
+
+// Instantiate instantiates the generic function to convert x to y. The type
+// arguments are inferred by unifying the input and output types of the
+// function with x and y. It returns false if they cannot be unified or the
+// type arguments do not satisfy the constraints.
+func Instantiate(fn Func, x, y Type) (Func, bool) {
+	tparams := typeParams(fn)
+	if tparams.Len() == 0 {
+		return nil, false
+	}
+
+	targs := make([]types.Type, tparams.Len())
+	if !unify(fn.X().Type(), x.Type(), tparams, targs) || !unify(fn.Y().Type(), y.Type(), tparams, targs) {
+		return nil, false
+	}
+	for _, targ := range targs {
+		if targ == nil {
+			return nil, false
+		}
+	}
+
+	obj := fn.Object().(*types.Func)
+	sig, err := types.Instantiate(nil, obj.Signature(), targs, true)
+	if err != nil {
+		return nil, false
+	}
+
+	// Unification is structural, so check the instance precisely.
+	inst, err := FuncOf[BothXY](types.NewFunc(obj.Pos(), obj.Pkg(), obj.Name(), sig.(*types.Signature)))
+	if err != nil || !inst.X().Identical(x) || !inst.Y().Identical(y) {
+		return nil, false
+	}
+
+	return function{
+		obj:    obj,
+		x:      x,
+		y:      y,
+		hasErr: fn.HasErr(),
+		hasOut: fn.HasOut(),
+		hasOk:  fn.HasOk(),
+		pos:    fn.Pos(),
+		targs:  &targs,
+	}, true
+}
+
+// unify matches the parameterized type with the concrete type t and records
+// the type arguments for the type parameters found in param.
+func unify(param, t types.Type, tparams *types.TypeParamList, targs []types.Type) bool {
+	param, t = types.Unalias(param), types.Unalias(t)
+
+	switch param := param.(type) {
+	case *types.TypeParam:
+		i := param.Index()
+		if i >= tparams.Len() || tparams.At(i) != param {
+			break
+		}
+		if targs[i] == nil {
+			targs[i] = t
+			return true
+		}
+		return types.Identical(targs[i], t)
+
+	case *types.Pointer:
+		t, ok := t.(*types.Pointer)
+		return ok && unify(param.Elem(), t.Elem(), tparams, targs)
+
+	case *types.Slice:
+		t, ok := t.(*types.Slice)
+		return ok && unify(param.Elem(), t.Elem(), tparams, targs)
+
+	case *types.Array:
+		t, ok := t.(*types.Array)
+		return ok && param.Len() == t.Len() && unify(param.Elem(), t.Elem(), tparams, targs)
+
+	case *types.Chan:
+		t, ok := t.(*types.Chan)
+		return ok && param.Dir() == t.Dir() && unify(param.Elem(), t.Elem(), tparams, targs)
+
+	case *types.Map:
+		t, ok := t.(*types.Map)
+		return ok && unify(param.Key(), t.Key(), tparams, targs) && unify(param.Elem(), t.Elem(), tparams, targs)
+
+	case *types.Named:
+		if param.TypeArgs().Len() == 0 {
+			break
+		}
+		t, ok := t.(*types.Named)
+		if !ok || param.Origin() != t.Origin() || param.TypeArgs().Len() != t.TypeArgs().Len() {
+			return false
+		}
+		for i := range param.TypeArgs().Len() {
+			if !unify(param.TypeArgs().At(i), t.TypeArgs().At(i), tparams, targs) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return types.Identical(param, t)
+}
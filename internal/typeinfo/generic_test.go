@@ -0,0 +1,101 @@
+package typeinfo_test
+
+import (
+	"go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sublee/convgen/internal/typeinfo"
+)
+
+const genericCode = `package p
+
+type Opt[T any] struct{ V T }
+
+func Ptr[T any](v T) *T { return &v }
+func Some[T any](v T) Opt[T] { return Opt[T]{v} }
+func Keys[K comparable, V any](m map[K]V) []K { return nil }
+func Unsigned[T ~uint | ~uint64](v T) *T { return &v }
+func Zero[T any](int) T { return *new(T) }
+func Pair[T, U any](T) T { return *new(T) }
+
+var (
+	i   int
+	s   string
+	u   uint
+	pi  *int
+	ps  *string
+	pu  *uint
+	oi  Opt[int]
+	os  Opt[string]
+	msi map[string]int
+	ss  []string
+	si  []int
+)
+`
+
+func TestInstantiate(t *testing.T) {
+	_, _, pkg, err := parse(genericCode)
+	require.NoError(t, err)
+
+	fn := func(name string) typeinfo.Func {
+		fn, err := typeinfo.FuncOf[typeinfo.BothXY](pkg.Scope().Lookup(name))
+		require.NoError(t, err)
+		require.True(t, typeinfo.IsGeneric(fn))
+		return fn
+	}
+	typ := func(name string) typeinfo.Type {
+		return typeinfo.TypeOf(pkg.Scope().Lookup(name).Type())
+	}
+
+	tests := []struct {
+		fn    string
+		x, y  string
+		targs []string
+	}{
+		{"Ptr", "i", "pi", []string{"int"}},
+		{"Ptr", "s", "ps", []string{"string"}},
+		{"Ptr", "i", "ps", nil},
+		{"Some", "i", "oi", []string{"int"}},
+		{"Some", "i", "os", nil},
+		{"Keys", "msi", "ss", []string{"string", "int"}},
+		{"Keys", "msi", "si", nil},
+		{"Unsigned", "u", "pu", []string{"uint"}},
+		{"Unsigned", "i", "pi", nil}, // constraint not satisfied
+		{"Zero", "i", "s", []string{"string"}},
+		{"Zero", "s", "s", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fn+"("+tt.x+")"+tt.y, func(t *testing.T) {
+			inst, ok := typeinfo.Instantiate(fn(tt.fn), typ(tt.x), typ(tt.y))
+			if tt.targs == nil {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.False(t, typeinfo.IsGeneric(inst))
+
+			var targs []string
+			for _, targ := range inst.TypeArgs() {
+				targs = append(targs, types.TypeString(targ, nil))
+			}
+			assert.Equal(t, tt.targs, targs)
+			assert.True(t, inst.X().Identical(typ(tt.x)))
+			assert.True(t, inst.Y().Identical(typ(tt.y)))
+		})
+	}
+}
+
+func TestUninferableTypeParams(t *testing.T) {
+	_, _, pkg, err := parse(genericCode)
+	require.NoError(t, err)
+
+	fn, err := typeinfo.FuncOf[typeinfo.BothXY](pkg.Scope().Lookup("Pair"))
+	require.NoError(t, err)
+
+	tparams := typeinfo.UninferableTypeParams(fn)
+	require.Len(t, tparams, 1)
+	assert.Equal(t, "U", tparams[0].Obj().Name())
+}
@@ -78,6 +78,9 @@ func (l *Lookup[T]) Del(x, y Type) bool {
 // Range iterates all registered functions.
 func (l *Lookup[T]) Range() iter.Seq[T] {
 	return func(yield func(T) bool) {
+		if l == nil {
+			return
+		}
 		for _, y := range l.mapY.Keys() {
 			mapX := l.mapY.At(y).(*typeutil.Map)
 			for _, x := range mapX.Keys() {
@@ -71,6 +71,8 @@ func TypeOf(t types.Type) Type {
 		return info
 	case *types.Signature:
 		return Type{T: t}
+	case *types.TypeParam:
+		return Type{T: t}
 	case *types.Tuple:
 		if tt.Len() == 0 {
 			return Type{T: t}
@@ -9,12 +9,22 @@ import (
 )
 
 // Analyzer validates the usage of Convgen in the package.
+//
+// With the -explain flag, it also reports the full match table of the named
+// converters as informational diagnostics with the "info" category.
 var Analyzer = &analysis.Analyzer{
 	Name: "convgen",
 	Doc:  "linter for convgen usage",
 	Run:  run,
 }
 
+// explain is the name of converters to explain.
+var explain string
+
+func init() {
+	Analyzer.Flags.StringVar(&explain, "explain", "", "report the full match table of the named converter")
+}
+
 func run(pass *analysis.Pass) (any, error) {
 	pkg := &packages.Package{
 		Name:      pass.Pkg.Name(),
@@ -25,32 +35,73 @@ func run(pass *analysis.Pass) (any, error) {
 		TypesInfo: pass.TypesInfo,
 	}
 
-	cg, err := convgeninternal.New(pkg)
+	// Analysis drivers do not tell the build tags, but they follow GOFLAGS.
+	cg, err := convgeninternal.New(pkg, convgeninternal.BuildContext(nil, ""))
 	if err != nil {
 		return nil, err
 	}
 
 	if err := cg.Build(); err != nil {
-		// Unroll all errors and report them
-		errs := []error{err}
-		for len(errs) != 0 {
-			err := errs[0]
-			errs = errs[1:]
-
-			if codeErr, ok := err.(*codefmt.CodeError); ok {
-				pass.Report(analysis.Diagnostic{
-					Pos:     codeErr.Pos(),
-					End:     codeErr.End(),
-					Message: codeErr.Unwrap().Error(),
-				})
-				continue
-			}
+		report(pass, err)
+		return nil, nil
+	}
 
-			if u, ok := err.(interface{ Unwrap() []error }); ok {
-				errs = append(errs, u.Unwrap()...)
+	// Warnings are found only when the build succeeds.
+	report(pass, cg.Warnings())
+	if explain != "" {
+		report(pass, cg.Explain(explain))
+	}
+	return nil, nil
+}
+
+// report unrolls all errors and reports them as diagnostics. Warnings and
+// informational diagnostics are reported with the "warning" and "info"
+// categories.
+func report(pass *analysis.Pass, err error) {
+	if err == nil {
+		return
+	}
+
+	errs := []error{err}
+	for len(errs) != 0 {
+		err := errs[0]
+		errs = errs[1:]
+
+		if codeErr, ok := err.(*codefmt.CodeError); ok {
+			var category string
+			if codeErr.Severity() != codefmt.SeverityError {
+				category = codeErr.Severity().String()
 			}
+
+			pass.Report(analysis.Diagnostic{
+				Pos:            codeErr.Pos(),
+				End:            codeErr.End(),
+				Category:       category,
+				Message:        codeErr.Unwrap().Error(),
+				SuggestedFixes: suggestedFixes(codeErr.Fixes()),
+			})
+			continue
+		}
+
+		if u, ok := err.(interface{ Unwrap() []error }); ok {
+			errs = append(errs, u.Unwrap()...)
 		}
 	}
+}
 
-	return nil, nil
+// suggestedFixes converts the fixes of a CodeError to analysis suggested fixes.
+func suggestedFixes(fixes []codefmt.Fix) []analysis.SuggestedFix {
+	var sfs []analysis.SuggestedFix
+	for _, fix := range fixes {
+		sf := analysis.SuggestedFix{Message: fix.Message}
+		for _, edit := range fix.Edits {
+			sf.TextEdits = append(sf.TextEdits, analysis.TextEdit{
+				Pos:     edit.Pos,
+				End:     edit.End,
+				NewText: []byte(edit.NewText),
+			})
+		}
+		sfs = append(sfs, sf)
+	}
+	return sfs
 }
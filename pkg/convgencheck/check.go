@@ -0,0 +1,277 @@
+// Package convgencheck provides test helpers for converters generated by
+// Convgen. They catch converters which compile and match but silently drop
+// data, for example, because of a bug in a function given to
+// convgen.MatchFunc.
+//
+//	func TestEncodeUser(t *testing.T) {
+//		convgencheck.Populated(t, EncodeUser)
+//	}
+//
+// The helpers call a converter with an input whose exported fields are all
+// non-zero and report the output fields which stay zero. Output fields which
+// the converter does not assign on purpose, such as fields skipped by
+// convgen.MatchSkip, are read from the mapping manifest generated by
+// "convgen -manifest json" and counted as intentional.
+package convgencheck
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// DefaultManifest is the path of the mapping manifest read by default. It is
+// the manifest generated next to the default output file in the package
+// directory, which is the working directory of tests.
+const DefaultManifest = "convgen_gen_manifest.json"
+
+// Option configures [Populated] and [ZeroFields].
+type Option func(*config)
+
+type config struct {
+	manifest string
+	optional bool
+	ignore   []string
+}
+
+// Manifest sets the path of the mapping manifest. An empty path disables the
+// manifest. Unlike [DefaultManifest], the file must exist.
+func Manifest(path string) Option {
+	return func(cfg *config) { cfg.manifest, cfg.optional = path, false }
+}
+
+// Ignore ignores the output fields by their paths, like "UserView.Profile.Age".
+// It is useful for fields which cannot be populated by a non-zero input, such
+// as enums whose members do not include 1.
+func Ignore(paths ...string) Option {
+	return func(cfg *config) { cfg.ignore = append(cfg.ignore, paths...) }
+}
+
+// Populated calls conv with a fully populated input and fails t if any output
+// field stays zero.
+func Populated[In, Out any](t testing.TB, conv func(In) Out, opts ...Option) {
+	t.Helper()
+	PopulatedErr(t, func(in In) (Out, error) { return conv(in), nil }, opts...)
+}
+
+// PopulatedErr is [Populated] for converters which may return an error. It
+// fails t if conv returns an error.
+func PopulatedErr[In, Out any](t testing.TB, conv func(In) (Out, error), opts ...Option) {
+	t.Helper()
+
+	zeros, err := ZeroFieldsErr(conv, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zeros) != 0 {
+		t.Errorf("output fields not populated by a fully populated input:\n\t%s", strings.Join(zeros, "\n\t"))
+	}
+}
+
+// ZeroFields calls conv with a fully populated input and returns the paths of
+// output fields which stay zero, like "UserView.Profile.Age". It returns an
+// error only if the manifest cannot be read. A missing [DefaultManifest] is
+// not an error, but a missing manifest given by [Manifest] is.
+//
+// Every exported field of the input is set to a non-zero value: true, 1, "1",
+// and a pointer, slice, or map with one populated element. Interfaces are left
+// nil, so output interfaces are not reported. Recursive types are populated
+// up to a limited depth.
+func ZeroFields[In, Out any](conv func(In) Out, opts ...Option) ([]string, error) {
+	return ZeroFieldsErr(func(in In) (Out, error) { return conv(in), nil }, opts...)
+}
+
+// ZeroFieldsErr is [ZeroFields] for converters which may return an error. The
+// error returned by conv is returned as is.
+func ZeroFieldsErr[In, Out any](conv func(In) (Out, error), opts ...Option) ([]string, error) {
+	cfg := config{manifest: DefaultManifest, optional: true}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	intended, err := readManifest(cfg.manifest, cfg.optional)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range cfg.ignore {
+		intended[path] = true
+	}
+
+	var in In
+	populate(reflect.ValueOf(&in).Elem(), 0)
+
+	out, err := conv(in)
+	if err != nil {
+		return nil, err
+	}
+
+	v := reflect.ValueOf(&out).Elem()
+	var zeros []string
+	findZeros(v, typeName(v.Type()), 0, func(path, field string) bool {
+		return intended[path] || intended[field]
+	}, &zeros)
+	return zeros, nil
+}
+
+// maxDepth is the depth limit of pointers, slices, and maps to populate.
+const maxDepth = 3
+
+// populate sets v to a non-zero value.
+func populate(v reflect.Value, depth int) {
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1)
+	case reflect.Complex64, reflect.Complex128:
+		v.SetComplex(1)
+	case reflect.String:
+		v.SetString("1")
+	case reflect.Pointer:
+		if depth < maxDepth {
+			p := reflect.New(v.Type().Elem())
+			populate(p.Elem(), depth+1)
+			v.Set(p)
+		}
+	case reflect.Slice:
+		if depth < maxDepth {
+			s := reflect.MakeSlice(v.Type(), 1, 1)
+			populate(s.Index(0), depth+1)
+			v.Set(s)
+		}
+	case reflect.Array:
+		for i := range v.Len() {
+			populate(v.Index(i), depth)
+		}
+	case reflect.Map:
+		if depth < maxDepth {
+			m := reflect.MakeMapWithSize(v.Type(), 1)
+			key := reflect.New(v.Type().Key()).Elem()
+			elem := reflect.New(v.Type().Elem()).Elem()
+			populate(key, depth+1)
+			populate(elem, depth+1)
+			m.SetMapIndex(key, elem)
+			v.Set(m)
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				populate(v.Field(i), depth)
+			}
+		}
+	}
+}
+
+// findZeros appends the paths of zero fields in v to zeros. path is the path
+// of v. intended reports whether a field is not assigned on purpose by its path
+// or its qualified name, like "UserView.Password".
+func findZeros(v reflect.Value, path string, depth int, intended func(path, field string) bool, zeros *[]string) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			findZeros(v.Elem(), path, depth+1, intended, zeros)
+		}
+	case reflect.Slice:
+		for i := range v.Len() {
+			findZeros(v.Index(i), fmt.Sprintf("%s[%d]", path, i), depth+1, intended, zeros)
+		}
+	case reflect.Array:
+		for i := range v.Len() {
+			findZeros(v.Index(i), fmt.Sprintf("%s[%d]", path, i), depth, intended, zeros)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			findZeros(iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key()), depth+1, intended, zeros)
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			sf := v.Type().Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+
+			fieldPath := path + "." + sf.Name
+			if intended(fieldPath, typeName(v.Type())+"."+sf.Name) || intended(fieldPath, v.Type().String()+"."+sf.Name) {
+				continue
+			}
+
+			f := v.Field(i)
+			switch {
+			case f.Kind() == reflect.Interface:
+				// Inputs do not populate interfaces.
+			case f.IsZero():
+				if !isContainer(f.Kind()) || depth < maxDepth {
+					*zeros = append(*zeros, fieldPath)
+				}
+			default:
+				findZeros(f, fieldPath, depth, intended, zeros)
+			}
+		}
+	}
+}
+
+// isContainer reports whether the kind is populated up to [maxDepth].
+func isContainer(kind reflect.Kind) bool {
+	return kind == reflect.Pointer || kind == reflect.Slice || kind == reflect.Map
+}
+
+// typeName returns the name of the type without its package name, or the
+// string of the type if it is not named.
+func typeName(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer && t.Name() == "" {
+		t = t.Elem()
+	}
+	if t.Name() != "" {
+		return t.Name()
+	}
+	return t.String()
+}
+
+// readManifest reads the mapping manifest and returns the qualified names of
+// output fields which converters do not assign on purpose. It returns an empty
+// set if path is empty, or if the file does not exist and it is optional.
+func readManifest(path string, optional bool) (map[string]bool, error) {
+	intended := make(map[string]bool)
+	if path == "" {
+		return intended, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if optional && errors.Is(err, fs.ErrNotExist) {
+		return intended, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var manifest struct {
+		Converters []struct {
+			Pairs []struct {
+				X, Y    string
+				Skipped bool
+			}
+		}
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+
+	for _, conv := range manifest.Converters {
+		for _, pair := range conv.Pairs {
+			if pair.Y != "" && (pair.X == "" || pair.Skipped) {
+				intended[pair.Y] = true
+			}
+		}
+	}
+	return intended, nil
+}
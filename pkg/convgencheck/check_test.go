@@ -0,0 +1,100 @@
+package convgencheck_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sublee/convgen/pkg/convgencheck"
+)
+
+type (
+	User struct {
+		ID       int
+		Name     string
+		Tags     []string
+		Profile  *Profile
+		Password string
+	}
+	Profile struct {
+		Age   int
+		Email string
+	}
+	UserView struct {
+		ID       string
+		Name     string
+		Tags     []string
+		Profile  *ProfileView
+		Password string
+	}
+	ProfileView struct {
+		Age   int
+		Email string
+	}
+)
+
+// encodeUser drops Profile.Email by mistake.
+func encodeUser(in User) UserView {
+	out := UserView{ID: strconv.Itoa(in.ID), Name: in.Name, Tags: in.Tags}
+	if in.Profile != nil {
+		out.Profile = &ProfileView{Age: in.Profile.Age}
+	}
+	return out
+}
+
+func TestZeroFields(t *testing.T) {
+	zeros, err := convgencheck.ZeroFields(encodeUser, convgencheck.Manifest(""))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"UserView.Profile.Email", "UserView.Password"}, zeros)
+}
+
+func TestZeroFieldsManifest(t *testing.T) {
+	manifest := filepath.Join(t.TempDir(), "convgen_gen_manifest.json")
+	require.NoError(t, os.WriteFile(manifest, []byte(`{
+		"package": "example.com/main",
+		"converters": [{
+			"name": "encodeUser",
+			"pairs": [
+				{"x": "User.ID", "y": "UserView.ID"},
+				{"x": "User.Password", "skipped": true, "reason": "skipped missing"},
+				{"y": "UserView.Password", "skipped": true, "reason": "skipped missing"}
+			]
+		}]
+	}`), 0o644))
+
+	zeros, err := convgencheck.ZeroFields(encodeUser, convgencheck.Manifest(manifest))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"UserView.Profile.Email"}, zeros)
+
+	zeros, err = convgencheck.ZeroFields(encodeUser, convgencheck.Manifest(manifest), convgencheck.Ignore("UserView.Profile.Email"))
+	require.NoError(t, err)
+	assert.Empty(t, zeros)
+}
+
+func TestZeroFieldsMissingManifest(t *testing.T) {
+	manifest := filepath.Join(t.TempDir(), "convgen_gen_manifest.json")
+	_, err := convgencheck.ZeroFields(encodeUser, convgencheck.Manifest(manifest))
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestZeroFieldsErr(t *testing.T) {
+	errFail := errors.New("fail")
+	_, err := convgencheck.ZeroFieldsErr(func(User) (UserView, error) { return UserView{}, errFail }, convgencheck.Manifest(""))
+	assert.ErrorIs(t, err, errFail)
+}
+
+func TestZeroFieldsRecursive(t *testing.T) {
+	type Node struct {
+		Value int
+		Next  *Node
+	}
+	zeros, err := convgencheck.ZeroFields(func(n Node) Node { return n }, convgencheck.Manifest(""))
+	require.NoError(t, err)
+	assert.Empty(t, zeros)
+}
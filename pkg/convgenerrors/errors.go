@@ -17,6 +17,10 @@ import (
 // any defined enum member.
 var ErrNoMatch = errors.New("no match found")
 
+// ErrNotOk is returned when a comma-ok conversion function imported by
+// convgen.ImportFuncOk reports false in a converter which returns an error.
+var ErrNotOk = errors.New("not ok")
+
 // Wrap creates a new error that wraps err with a prefix indicating the object
 // being converted. The returned error message includes the conversion context.
 //
@@ -0,0 +1,152 @@
+// Package convgenplugin provides the extension point for organization-specific
+// conversions which need generated code rather than a function call, such as
+// money types, ID wrappers, or custom optional types.
+//
+// A plugin is an [Assigner] registered by [Register], typically in the init
+// function of the plugin package:
+//
+//	func init() {
+//		convgenplugin.Register("optional", optionalAssigner{})
+//	}
+//
+// Plugins take effect in a custom build of the generator which imports them.
+// [github.com/sublee/convgen/pkg/convgenrun.Main] runs the same command as the
+// convgen command:
+//
+//	package main
+//
+//	import (
+//		"github.com/sublee/convgen/pkg/convgenrun"
+//
+//		_ "example.com/convgen-optional"
+//	)
+//
+//	func main() {
+//		convgenrun.Main()
+//	}
+//
+// Convgen tries plugins after the functions imported by convgen.ImportFunc or
+// convgen.MatchFunc, and before its built-in conversions. So users can still
+// override a plugin for specific types.
+//
+// The analyzer in [github.com/sublee/convgen/pkg/convgenanalysis] knows only
+// the plugins imported by the program running it. gopls, go vet, and the
+// golangci-lint plugin of Convgen do not import any, so they report the pairs
+// handled by plugins as "cannot convert". Run the analyzer in a custom build
+// which imports the plugins instead:
+//
+//	package main
+//
+//	import (
+//		"golang.org/x/tools/go/analysis/singlechecker"
+//
+//		"github.com/sublee/convgen/pkg/convgenanalysis"
+//
+//		_ "example.com/convgen-optional"
+//	)
+//
+//	func main() {
+//		singlechecker.Main(convgenanalysis.Analyzer)
+//	}
+package convgenplugin
+
+import (
+	"errors"
+	"fmt"
+	"go/types"
+	"iter"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// Assigner generates code to assign an input value to an output value.
+type Assigner interface {
+	// Assign returns an [Assignment] which converts x to y. If the assigner
+	// does not handle the pair, it should return [ErrSkip] to let Convgen try
+	// the next way to convert. Any other error fails the conversion.
+	Assign(b Builder, x, y types.Type) (Assignment, error)
+}
+
+// Assignment is a conversion built by an [Assigner].
+type Assignment interface {
+	// RequiresErr reports whether the generated code may fail with an error.
+	// An assignment which requires an error can be used only if
+	// [Builder.AllowsErr] is true.
+	RequiresErr() bool
+
+	// WriteAssign writes code to assign varX to varY. varX and varY are
+	// expressions of the input and output values. varY is addressable. If
+	// [Assignment.RequiresErr] is true, the code should set varErr to a non-nil
+	// error on failure. The code must not declare varX, varY, or varErr.
+	WriteAssign(w Writer, varX, varY, varErr string)
+}
+
+// Builder provides the context of the conversion to an [Assigner].
+type Builder interface {
+	// AllowsErr reports whether the converter being built may return an
+	// error.
+	AllowsErr() bool
+
+	// Build builds an assignment which converts x to y in the same way as
+	// Convgen converts the fields. It is useful to convert elements of a
+	// wrapper type, like T of Optional[T]. It tries the registered plugins
+	// too.
+	Build(x, y types.Type) (Assignment, error)
+}
+
+// Writer is a restricted writer of generated code.
+type Writer interface {
+	// Printf writes formatted code. In addition to the verbs of the fmt
+	// package, %t formats a [types.Type] as it should be written in the
+	// generated file, and imports the packages it refers to.
+	Printf(format string, args ...any)
+
+	// Import imports a package and returns the name to refer to it in the
+	// generated file. name is the preferred name, or empty to use the package
+	// name. The returned name may differ from name to avoid conflicts.
+	Import(path, name string) string
+
+	// Name returns a unique identifier based on name to declare a local
+	// variable.
+	Name(name string) string
+}
+
+// ErrSkip is returned by [Assigner.Assign] if the assigner does not handle the
+// given types.
+var ErrSkip = errors.New("skip")
+
+var (
+	mu        sync.RWMutex
+	names     []string
+	assigners = make(map[string]Assigner)
+)
+
+// Register registers an assigner with a unique name. Assigners are tried in the
+// order of registration. It panics if the name is already registered.
+func Register(name string, as Assigner) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := assigners[name]; ok {
+		panic(fmt.Sprintf("convgenplugin: %q already registered", name))
+	}
+	names = append(names, name)
+	assigners[name] = as
+}
+
+// Assigners returns the registered assigners by their names in the order of
+// registration.
+func Assigners() iter.Seq2[string, Assigner] {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	ordered, m := slices.Clone(names), maps.Clone(assigners)
+	return func(yield func(string, Assigner) bool) {
+		for _, name := range ordered {
+			if !yield(name, m[name]) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,217 @@
+// Package convgenrun runs the Convgen code generator from Go programs, such as
+// build tools, without shelling out to the convgen command.
+//
+//	result, err := convgenrun.Generate(ctx, convgenrun.Options{
+//		Dir:      "/path/to/module",
+//		Patterns: []string{"./..."},
+//	})
+//	for _, pkg := range result.Packages {
+//		for path, code := range pkg.Files {
+//			// write code to path
+//		}
+//	}
+//
+// Generate never writes files. It is up to the caller to write the generated
+// files and to remove the orphaned files.
+package convgenrun
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	convgeninternal "github.com/sublee/convgen/internal/convgen"
+	"github.com/sublee/convgen/internal/diag"
+)
+
+// Options configures [Generate].
+type Options struct {
+	// Dir is the directory to load packages from. File paths in [Result] are
+	// relative to it. If it is empty, the current directory is used.
+	Dir string
+
+	// Env is the environment variables for the go command. If it is nil, the
+	// current environment is used.
+	Env []string
+
+	// Tags is the comma-separated build tags in addition to "convgen".
+	Tags string
+
+	// Tests includes test files of the packages.
+	Tests bool
+
+	// Patterns are the package patterns to generate code for, like "./...".
+	Patterns []string
+
+	// OutFile is the name of the file to generate in each package. If it is
+	// empty, "convgen_gen.go" is used.
+	OutFile string
+
+	// Split generates one file per Convgen file, like "user.go" into
+	// "user_gen.go". Implicit converters shared by the files are generated in
+	// OutFile.
+	Split bool
+
+	// Overlay maps absolute file paths to their contents to use instead of the
+	// files on disk. It is useful to generate code for unsaved files.
+	Overlay map[string][]byte
+
+	// Strict treats warnings as errors.
+	Strict bool
+
+	// Jobs is the maximum number of packages to build concurrently. If it is
+	// less than 1, packages are built one by one.
+	Jobs int
+}
+
+// Result is the result of [Generate].
+type Result struct {
+	// Packages are the packages with generated files sorted by their IDs.
+	Packages []Package
+
+	// Orphans are paths of files previously generated by Convgen which are no
+	// longer generated. They should be removed.
+	Orphans []string
+
+	// Diagnostics are the errors and warnings reported by Convgen.
+	Diagnostics []Diagnostic
+}
+
+// Package is a package with files generated by Convgen.
+type Package struct {
+	// ID is the unique identifier of the package in the go command, such as
+	// "example.com/foo" or "example.com/foo [example.com/foo.test]" for a
+	// test variant.
+	ID string
+
+	// Files maps paths of generated files to their contents.
+	Files map[string][]byte
+}
+
+// Diagnostic is an error or a warning reported by Convgen.
+type Diagnostic struct {
+	// Severity is either "error" or "warning".
+	Severity string
+
+	// File, Line, and Column locate the start of the problem. File is empty if
+	// the problem has no position. EndLine and EndColumn locate the end of the
+	// problem if known.
+	File               string
+	Line, Column       int
+	EndLine, EndColumn int
+
+	// Converter is the name of the converter with the problem, if any.
+	Converter string
+
+	// Message describes the problem without its position.
+	Message string
+
+	// Matches is the table of matches for a failure to match fields, enum
+	// members, or union implementations.
+	Matches []Match
+}
+
+// Match is a row of the match table of a [Diagnostic].
+type Match struct {
+	// OK reports whether the match is valid.
+	OK bool
+
+	// X and Y are the matched names. A missing side is "?".
+	X, Y string
+
+	// Skipped reports whether the match is skipped by an option.
+	Skipped bool
+
+	// Reason explains the match, like "forced at main.go:10:5" or "missing".
+	Reason string
+}
+
+// Generate runs Convgen for the packages matched by the patterns and returns
+// the generated files. It does not write any files.
+//
+// Errors and warnings reported by Convgen are returned as [Result.Diagnostics].
+// If there is any error, the returned error is not nil and no files are
+// returned. The error describes all errors in a human-readable form.
+func Generate(ctx context.Context, opts Options) (Result, error) {
+	if opts.Dir == "" {
+		dir, err := filepath.Abs(".")
+		if err != nil {
+			return Result{}, err
+		}
+		opts.Dir = dir
+	}
+	if opts.OutFile == "" {
+		opts.OutFile = "convgen_gen.go"
+	}
+	setVersion()
+
+	out, err := convgeninternal.Main(ctx, convgeninternal.Options{
+		WD:       opts.Dir,
+		Env:      opts.Env,
+		Tags:     opts.Tags,
+		Tests:    opts.Tests,
+		OutFile:  opts.OutFile,
+		Split:    opts.Split,
+		Patterns: opts.Patterns,
+		Overlay:  opts.Overlay,
+		Strict:   opts.Strict,
+		Jobs:     opts.Jobs,
+	})
+
+	result := Result{Orphans: out.Orphans}
+	for _, d := range diag.Collect(opts.Dir, errors.Join(err, out.Warnings)) {
+		result.Diagnostics = append(result.Diagnostics, newDiagnostic(d))
+	}
+	if err != nil {
+		return result, err
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(out.Packages)) {
+		pkg := Package{ID: id, Files: make(map[string][]byte)}
+		for _, path := range out.Packages[id] {
+			pkg.Files[path] = out.Files[path]
+		}
+		result.Packages = append(result.Packages, pkg)
+	}
+	return result, nil
+}
+
+// setVersion sets the version in the header of generated files as the convgen
+// command does, unless the command has set it already.
+var setVersion = sync.OnceFunc(func() {
+	if convgeninternal.Version == "" {
+		convgeninternal.Version = version()
+	}
+})
+
+// ReadOverlay reads an overlay file in the format of "go build -overlay" for
+// [Options.Overlay]. Relative paths in the file are resolved against dir.
+func ReadOverlay(dir, path string) (map[string][]byte, error) {
+	return convgeninternal.ReadOverlay(dir, path)
+}
+
+func newDiagnostic(d diag.Diagnostic) Diagnostic {
+	out := Diagnostic{
+		Severity:  d.Severity,
+		File:      d.File,
+		Line:      d.Line,
+		Column:    d.Column,
+		EndLine:   d.EndLine,
+		EndColumn: d.EndColumn,
+		Converter: d.Converter,
+		Message:   d.Message,
+	}
+	for _, m := range d.Matches {
+		out.Matches = append(out.Matches, Match{
+			OK:      m.Status == "ok",
+			X:       m.X,
+			Y:       m.Y,
+			Skipped: m.Skipped,
+			Reason:  m.Reason,
+		})
+	}
+	return out
+}
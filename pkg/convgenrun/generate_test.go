@@ -0,0 +1,104 @@
+package convgenrun_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sublee/convgen/pkg/convgenrun"
+)
+
+// newModule writes a module which uses a copy of Convgen into a temporary
+// directory and returns its path.
+func newModule(t *testing.T, mainGo string) string {
+	convgenGo, err := os.ReadFile(filepath.FromSlash("../../convgen.go"))
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"convgen/go.mod":     "module github.com/sublee/convgen\n\ngo 1.25.0\n",
+		"convgen/convgen.go": string(convgenGo),
+		"app/go.mod": "module example.com/app\n\ngo 1.25.0\n\n" +
+			"require github.com/sublee/convgen v0.0.0\n\n" +
+			"replace github.com/sublee/convgen => ../convgen\n",
+		"app/main.go": mainGo,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return filepath.Join(dir, "app")
+}
+
+const mainGo = `//go:build convgen
+
+package main
+
+import "github.com/sublee/convgen"
+
+type (
+	X struct{ Name string }
+	Y struct{ Name string }
+)
+
+var conv = convgen.Struct[X, Y](nil)
+
+func main() {}
+`
+
+func TestGenerate(t *testing.T) {
+	dir := newModule(t, mainGo)
+
+	result, err := convgenrun.Generate(t.Context(), convgenrun.Options{
+		Dir:      dir,
+		Patterns: []string{"."},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, result.Diagnostics)
+
+	require.Len(t, result.Packages, 1)
+	assert.Equal(t, "example.com/app", result.Packages[0].ID)
+	assert.Contains(t, string(result.Packages[0].Files["convgen_gen.go"]), "out.Name = in.Name")
+}
+
+func TestGenerateOverlay(t *testing.T) {
+	dir := newModule(t, mainGo)
+
+	// Y has a field which X does not have only in the overlay.
+	overlay := map[string][]byte{
+		filepath.Join(dir, "main.go"): []byte(`//go:build convgen
+
+package main
+
+import "github.com/sublee/convgen"
+
+type (
+	X struct{ Name string }
+	Y struct{ Name, Email string }
+)
+
+var conv = convgen.Struct[X, Y](nil)
+
+func main() {}
+`),
+	}
+
+	result, err := convgenrun.Generate(t.Context(), convgenrun.Options{
+		Dir:      dir,
+		Patterns: []string{"."},
+		Overlay:  overlay,
+	})
+	require.Error(t, err)
+	assert.Empty(t, result.Packages)
+
+	require.Len(t, result.Diagnostics, 1)
+	d := result.Diagnostics[0]
+	assert.Equal(t, "error", d.Severity)
+	assert.Equal(t, "main.go", d.File)
+	assert.Equal(t, "conv", d.Converter)
+	assert.Contains(t, d.Matches, convgenrun.Match{OK: false, X: "?", Y: "Email", Reason: "missing"})
+}
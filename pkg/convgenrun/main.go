@@ -0,0 +1,31 @@
+package convgenrun
+
+import (
+	"runtime/debug"
+
+	"github.com/sublee/convgen/internal/cli"
+)
+
+// Main runs the convgen command with the command-line arguments, as the
+// convgen command does. It exits the process on failure.
+//
+// It is for custom builds of the convgen command with plugins registered by
+// [github.com/sublee/convgen/pkg/convgenplugin.Register].
+func Main() {
+	cli.Main(version())
+}
+
+// version returns the version of the Convgen module which the running program
+// depends on.
+func version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev"
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == "github.com/sublee/convgen" {
+			return dep.Version
+		}
+	}
+	return "dev"
+}
@@ -0,0 +1,221 @@
+// Package convgentest runs golden tests for Convgen directives, such as
+// modules of functions shared by converter libraries. It is the public form of
+// the harness Convgen uses for its own tests.
+//
+//	func TestConverters(t *testing.T) {
+//		convgentest.Run(t, "testdata", convgentest.Options{Run: true})
+//	}
+//
+// Each subdirectory of the testdata directory is a test case. It is a package
+// in the module under test, so it may import the packages being tested:
+//
+//	testdata/
+//	└── case1/
+//	    ├── main.go                    // //go:build convgen
+//	    └── want/
+//	        ├── convgen_gen.go.golden  // generated code
+//	        ├── diagnostics.golden     // errors and warnings, if any
+//	        └── output.golden          // output of the program with Options.Run
+//
+// Run "go test -convgentest.update" to write the golden files from the
+// current results. Golden files which are no longer generated are removed.
+package convgentest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/sublee/convgen/pkg/convgenrun"
+)
+
+var update = flag.Bool("convgentest.update", false, "update golden files of convgentest")
+
+const (
+	// wantDir is the directory of golden files in each test case.
+	wantDir = "want"
+
+	// goldenExt is the extension of golden files of generated files.
+	goldenExt = ".golden"
+
+	diagnosticsGolden = "diagnostics.golden"
+	outputGolden      = "output.golden"
+)
+
+// Options configures [Run].
+type Options struct {
+	// Tags is the comma-separated build tags in addition to "convgen".
+	Tags string
+
+	// Split generates one file per Convgen file. See [convgenrun.Options].
+	Split bool
+
+	// Strict treats warnings as errors.
+	Strict bool
+
+	// Run compiles each test case which has no errors as a main package with
+	// the generated code and compares its output with output.golden.
+	Run bool
+}
+
+// Run runs Convgen on each test case in dir and compares the generated files,
+// diagnostics, and optionally the program output with the golden files. Each
+// test case runs as a parallel subtest named after its directory. Directories
+// starting with "." or "_" are ignored.
+func Run(t *testing.T, dir string, opts Options) {
+	t.Helper()
+
+	ents, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ent := range ents {
+		name := ent.Name()
+		if !ent.IsDir() || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+			continue
+		}
+
+		root, err := filepath.Abs(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			runCase(t, root, opts)
+		})
+	}
+}
+
+// runCase runs a test case in the root directory.
+func runCase(t *testing.T, root string, opts Options) {
+	result, err := convgenrun.Generate(t.Context(), convgenrun.Options{
+		Dir:      root,
+		Tags:     opts.Tags,
+		Patterns: []string{"."},
+		Split:    opts.Split,
+		Strict:   opts.Strict,
+	})
+
+	check(t, filepath.Join(root, wantDir, diagnosticsGolden), formatDiagnostics(result.Diagnostics))
+
+	files := make(map[string][]byte)
+	for _, pkg := range result.Packages {
+		for path, code := range pkg.Files {
+			files[path] = code
+		}
+	}
+	for path, code := range files {
+		check(t, filepath.Join(root, wantDir, filepath.Base(path)+goldenExt), code)
+	}
+	checkStale(t, root, files)
+
+	if opts.Run && err == nil {
+		check(t, filepath.Join(root, wantDir, outputGolden), runProgram(t, root, opts.Tags, files))
+	}
+}
+
+// check compares have with the golden file. An empty have means no golden
+// file. It updates the golden file instead if the update flag is set.
+func check(t *testing.T, golden string, have []byte) {
+	t.Helper()
+
+	if *update {
+		if len(have) == 0 {
+			if err := os.Remove(golden); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				t.Fatal(err)
+			}
+			return
+		}
+		if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(golden, have, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(want, have) {
+		t.Errorf("%s does not match; rerun with -convgentest.update to update it\n--- want\n%s\n--- have\n%s", golden, want, have)
+	}
+}
+
+// checkStale reports or removes golden files of generated files which are no
+// longer generated.
+func checkStale(t *testing.T, root string, files map[string][]byte) {
+	t.Helper()
+
+	goldens, err := filepath.Glob(filepath.Join(root, wantDir, "*.go"+goldenExt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, golden := range goldens {
+		name := strings.TrimSuffix(filepath.Base(golden), goldenExt)
+		if _, ok := files[name]; ok {
+			continue
+		}
+		check(t, golden, nil)
+	}
+}
+
+// formatDiagnostics formats diagnostics one by one, like
+// "main.go:10:5: error: invalid match between User and UserView".
+func formatDiagnostics(diags []convgenrun.Diagnostic) []byte {
+	var b bytes.Buffer
+	for _, d := range diags {
+		if d.File != "" {
+			fmt.Fprintf(&b, "%s:%d:%d: ", filepath.ToSlash(d.File), d.Line, d.Column)
+		}
+		fmt.Fprintf(&b, "%s: %s\n", d.Severity, d.Message)
+	}
+	return b.Bytes()
+}
+
+// runProgram runs the test case as a main package with the generated files
+// given by an overlay, so that the test case directory is never written. The
+// build tags are the same as for Convgen except "convgen". It returns the
+// combined output of the program.
+func runProgram(t *testing.T, root, tags string, files map[string][]byte) []byte {
+	t.Helper()
+
+	tmp := t.TempDir()
+	overlay := struct{ Replace map[string]string }{Replace: make(map[string]string)}
+	for _, path := range slices.Sorted(maps.Keys(files)) {
+		src := filepath.Join(tmp, filepath.Base(path))
+		if err := os.WriteFile(src, files[path], 0o644); err != nil {
+			t.Fatal(err)
+		}
+		overlay.Replace[filepath.Join(root, path)] = src
+	}
+
+	data, err := json.Marshal(overlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	overlayPath := filepath.Join(tmp, "overlay.json")
+	if err := os.WriteFile(overlayPath, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command("go", "run", "-tags="+tags, "-overlay="+overlayPath, ".")
+	cmd.Dir = root
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go run failed: %s\n%s", err, out)
+	}
+	return out
+}
@@ -0,0 +1,11 @@
+package convgentest_test
+
+import (
+	"testing"
+
+	"github.com/sublee/convgen/pkg/convgentest"
+)
+
+func TestRun(t *testing.T) {
+	convgentest.Run(t, "testdata", convgentest.Options{Run: true})
+}
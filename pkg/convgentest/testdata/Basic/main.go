@@ -0,0 +1,29 @@
+//go:build convgen
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/sublee/convgen"
+)
+
+type (
+	User struct {
+		ID   int
+		Name string
+	}
+	UserView struct {
+		ID   string
+		Name string
+	}
+)
+
+var mod = convgen.Module(convgen.ImportFunc(strconv.Itoa))
+
+var EncodeUser = convgen.Struct[User, UserView](mod)
+
+func main() {
+	fmt.Println(EncodeUser(User{ID: 42, Name: "Alice"}))
+}
@@ -0,0 +1,14 @@
+//go:build convgen
+
+package main
+
+import "github.com/sublee/convgen"
+
+type (
+	User     struct{ Name string }
+	UserView struct{ Name, Email string }
+)
+
+var EncodeUser = convgen.Struct[User, UserView](nil)
+
+func main() {}
@@ -12,6 +12,8 @@ func int2string(int) string { return "" }
 
 func string2int(string) (int, error) { return 0, nil }
 
+func lookupInt(string) (int, bool) { return 0, false }
+
 type (
 	TheInt = int
 	MyInt  int
@@ -31,4 +33,8 @@ var _ = convgen.Module(
 	convgen.ImportFuncErr(strconv.Atoi),
 	convgen.ImportFuncErr(string2int),                                  // want `duplicate string to int converter`
 	convgen.ImportFuncErr(func(string) (int, error) { return 0, nil }), // want `duplicate string to int converter`
+
+	// ImportFuncOk
+	convgen.ImportFuncOk(lookupInt),                                          // want `duplicate string to int converter`
+	convgen.ImportFuncOkOr(func(string) (int, bool) { return 0, false }, -1), // want `duplicate string to int converter`
 )
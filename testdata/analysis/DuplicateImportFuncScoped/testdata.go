@@ -0,0 +1,32 @@
+//go:build convgen
+
+package testdata
+
+import (
+	"strconv"
+
+	"github.com/sublee/convgen"
+)
+
+func int2string(int) string { return "" }
+
+type (
+	User     struct{ ID int }
+	UserView struct{ ID string }
+)
+
+var mod = convgen.Module(
+	convgen.ImportFunc(strconv.Itoa),
+)
+
+var (
+	// ok, because the converter-scoped function overrides the module one
+	convUser = convgen.Struct[User, UserView](mod,
+		convgen.ImportFunc(int2string),
+	)
+
+	convUser2 = convgen.Struct[User, UserView](nil,
+		convgen.ImportFunc(int2string),
+		convgen.ImportFunc(strconv.Itoa), // want `duplicate int to string converter`
+	)
+)
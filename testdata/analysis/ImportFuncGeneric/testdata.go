@@ -0,0 +1,34 @@
+//go:build convgen
+
+package testdata
+
+import (
+	"strconv"
+
+	"github.com/sublee/convgen"
+)
+
+func Ptr[T any](v T) *T { return &v }
+
+func Zero[T any](int) T { return *new(T) }
+
+func Convert[T, U any](T) U { return *new(U) }
+
+func Deref[T any](p *T) (T, error) { return *p, nil }
+
+var _ = convgen.Module(
+	convgen.ImportFuncGeneric(Ptr[any]),
+	convgen.ImportFuncGeneric(Zero[any]),                          // ok, T appears in the output
+	convgen.ImportFuncGeneric(strconv.Itoa),                       // want `cannot use strconv.Itoa as generic function; use convgen.ImportFunc for non-generic function`
+	convgen.ImportFuncGeneric(func(int) string { return "" }),     // want `cannot use func\(int\) string \{ return "" \} as generic function`
+	convgen.ImportFuncGeneric(Convert[any, any]),                  // ok
+	convgen.ImportFuncGenericErr(Deref[any]),                      // ok
+	convgen.ImportFuncGeneric(func(v int) *int { return Ptr(v) }), // want `cannot use .* as generic function`
+	convgen.ImportFuncGeneric(Ptr[int]),                           // want `duplicate T to \*T converter`
+)
+
+func Pair[T, U any](T) T { return *new(T) }
+
+var _ = convgen.Module(
+	convgen.ImportFuncGeneric(Pair[int, string]), // want `cannot infer type parameter U of Pair from its input and output types`
+)
@@ -0,0 +1,44 @@
+//go:build convgen
+
+package testdata
+
+import (
+	"github.com/sublee/convgen"
+)
+
+func Ptr[T any](v T) *T { return &v }
+
+func PtrOf[T comparable](v T) *T { return &v }
+
+func intPtr(v int) *int { return &v }
+
+type (
+	X struct{ V int }
+	Y struct{ V *int }
+	Z struct{ V *string }
+)
+
+var (
+	amb = convgen.Module(
+		convgen.ImportFuncGeneric(Ptr[any]),
+		convgen.ImportFuncGeneric(PtrOf[int]),
+	)
+	convXY = convgen.Struct[X, Y](amb) // want `ambiguous instantiation to convert X.V \(int\) to Y.V \(\*int\)`
+
+	// ok, because the concrete function takes precedence
+	prec = convgen.Module(
+		convgen.ImportFuncGeneric(Ptr[any]),
+		convgen.ImportFuncGeneric(PtrOf[int]),
+		convgen.ImportFunc(intPtr),
+	)
+	precXY = convgen.Struct[X, Y](prec)
+
+	// ok, because string does not satisfy the constraint of Unsigned
+	cons = convgen.Module(
+		convgen.ImportFuncGeneric(Ptr[any]),
+		convgen.ImportFuncGeneric(UnsignedPtr[uint]),
+	)
+	consXZ = convgen.Struct[struct{ V string }, Z](cons)
+)
+
+func UnsignedPtr[T ~uint | ~uint64](v T) *T { return &v }
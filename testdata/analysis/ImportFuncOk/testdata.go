@@ -0,0 +1,25 @@
+//go:build convgen
+
+package testdata
+
+import (
+	"github.com/sublee/convgen"
+)
+
+type Country int
+
+func ParseCountry(string) (Country, bool) { return 0, false }
+
+func parseLevel(string) (int, bool) { return 0, false }
+
+type (
+	Input  struct{ Country string }
+	Output struct{ Country Country }
+)
+
+var mod = convgen.Module(
+	convgen.ImportFuncOkOr(ParseCountry, 0),
+	convgen.ImportFuncOkOr(parseLevel, -1), // want `ineffective convgen.ImportFuncOkOr: parseLevel is never called`
+)
+
+var convInput = convgen.Struct[Input, Output](mod)
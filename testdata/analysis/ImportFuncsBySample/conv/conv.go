@@ -0,0 +1,11 @@
+package conv
+
+import "strconv"
+
+type ID int
+
+func FormatID(id ID) string { return strconv.Itoa(int(id)) }
+
+func Itoa(i int) string { return strconv.Itoa(i) }
+
+func FormatInt(i int) string { return strconv.Itoa(i) }
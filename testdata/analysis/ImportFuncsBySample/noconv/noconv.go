@@ -0,0 +1,7 @@
+package noconv
+
+type T struct{}
+
+func Validate(T) error { return nil }
+
+func Join(...string) string { return "" }
@@ -0,0 +1,25 @@
+//go:build convgen
+
+package testdata
+
+import (
+	"strconv"
+
+	"github.com/sublee/convgen"
+	"github.com/sublee/convgen/testdata/analysis/ImportFuncsBySample/conv"
+	"github.com/sublee/convgen/testdata/analysis/ImportFuncsBySample/noconv"
+)
+
+var (
+	_ = convgen.Module(convgen.ImportFuncsBySample(conv.FormatID))   // want `duplicate int to string converter conv.Itoa\n\tprevious import of conv.FormatInt`
+	_ = convgen.Module(convgen.ImportFuncsBySample(conv.ID(0)))      // want `duplicate int to string converter conv.Itoa`
+	_ = convgen.Module(convgen.ImportFuncsBySample((*conv.ID)(nil))) // want `duplicate int to string converter conv.Itoa`
+
+	_ = convgen.Module(
+		convgen.ImportFunc(strconv.Itoa),
+		convgen.ImportFuncsBySample(noconv.T{}), // want `no exported function of package github.com/sublee/convgen/testdata/analysis/ImportFuncsBySample/noconv fits conversion function`
+	)
+
+	_ = convgen.Module(convgen.ImportFuncsBySample(nil)) // want `cannot use nil as sample`
+	_ = convgen.Module(convgen.ImportFuncsBySample(42))  // want `cannot find package of 42`
+)
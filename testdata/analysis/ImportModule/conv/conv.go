@@ -0,0 +1,21 @@
+//go:build convgen
+
+package conv
+
+import (
+	"strconv"
+
+	"github.com/sublee/convgen"
+)
+
+type (
+	Money     struct{ Amount int }
+	MoneyView struct{ Amount string }
+)
+
+var Mod = convgen.Module(convgen.ImportFunc(strconv.Itoa), convgen.ImportFuncErr(strconv.Atoi))
+
+var (
+	EncodeMoney = convgen.Struct[Money, MoneyView](Mod)
+	DecodeMoney = convgen.StructErr[MoneyView, Money](Mod)
+)
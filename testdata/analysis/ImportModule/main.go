@@ -0,0 +1,24 @@
+//go:build convgen
+
+package testdata
+
+import (
+	"github.com/sublee/convgen"
+	"github.com/sublee/convgen/testdata/analysis/ImportModule/conv"
+)
+
+type (
+	Order     struct{ Price conv.Money }
+	OrderView struct{ Price conv.MoneyView }
+)
+
+var (
+	mod         = convgen.Module(convgen.ImportModule(conv.Mod))
+	EncodeOrder = convgen.Struct[Order, OrderView](mod)    // ok
+	DecodeOrder = convgen.StructErr[OrderView, Order](mod) // ok
+)
+
+var (
+	errorless   = convgen.Module(convgen.ImportModule(conv.Mod))
+	decodeOrder = convgen.Struct[OrderView, Order](errorless) // want `cannot call conv.DecodeMoney to convert OrderView.Price \(conv.MoneyView\) to Order.Price \(conv.Money\): error return required`
+)
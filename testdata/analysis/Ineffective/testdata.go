@@ -0,0 +1,48 @@
+//go:build convgen
+
+package testdata
+
+import (
+	"strconv"
+
+	"github.com/sublee/convgen"
+)
+
+type User struct {
+	ID   int
+	Name string
+}
+
+func (User) GetEmail() string { return "" }
+
+type Person struct {
+	ID    int
+	Name  string
+	Email string
+}
+
+func (p *Person) SetEmail(email string) { p.Email = email }
+
+var mod = convgen.Module(
+	convgen.ImportFunc(strconv.Itoa),                   // want `ineffective convgen.ImportFunc: strconv.Itoa is never called`
+	convgen.RenameReplace("Foo", "Bar", "", ""),        // want `ineffective convgen.RenameReplace: no name is renamed`
+	convgen.DiscoverGetters("Get", ""),                 // ok, GetEmail is discovered
+	convgen.ForEnum(convgen.RenameToLower(true, true)), // want `ineffective convgen.ForEnum: no enum converter in the module`
+	convgen.ForStruct(convgen.RenameToLower(true, true)),
+)
+
+var convUser = convgen.Struct[User, Person](mod,
+	convgen.DiscoverSetters("Put", ""), // want `ineffective convgen.DiscoverSetters: no method matches prefix "Put" and suffix ""`
+)
+
+func formatID(int) string { return "" }
+
+type Account struct {
+	Name string
+}
+
+var convAccount = convgen.Struct[Person, Account](mod,
+	convgen.MatchSkip(Person{}.ID, nil),
+	convgen.MatchSkip(Person{}.Email, nil),
+	convgen.ImportFunc(formatID), // want `ineffective convgen.ImportFunc: formatID is never called`
+)
@@ -0,0 +1,30 @@
+//go:build convgen
+
+package testdata
+
+import (
+	"strconv"
+
+	"github.com/sublee/convgen"
+)
+
+var (
+	base = convgen.Module(convgen.ImportFunc(strconv.Itoa))
+
+	// ok, overrides strconv.Itoa instead of duplicating it
+	_ = convgen.Module(convgen.Extend(base), convgen.ImportFunc(func(int) string { return "" }))
+
+	// ok, extends a module declared later
+	_ = convgen.Module(convgen.Extend(later))
+
+	_ = convgen.Module(convgen.ImportFunc(strconv.Itoa), convgen.Extend(base)) // want `convgen.Extend must be the first option of convgen.Module`
+	_ = convgen.Module(convgen.Extend(nil))                                    // want `base module must be package-level variable`
+
+	later = convgen.Module()
+
+	// ok, drops the functions imported before convgen.ImportFuncReset
+	_ = convgen.Module(convgen.Extend(base), convgen.ImportFunc(func(int) string { return "" }), convgen.ImportFuncReset(), convgen.ImportFunc(strconv.Itoa))
+
+	// convgen.ImportFuncReset drops the inherited functions, so the rest are not overrides
+	_ = convgen.Module(convgen.Extend(base), convgen.ImportFuncReset(), convgen.ImportFunc(strconv.Itoa), convgen.ImportFunc(func(int) string { return "" })) // want `duplicate int to string converter`
+)
@@ -10,7 +10,7 @@ import (
 
 var mod = convgen.Module() // ok, very valid
 
-var Mod = convgen.Module() // want `cannot export module "Mod"; removed at code generation`
+var Mod = convgen.Module() // ok, may be imported by convgen.ImportModule
 
 var _ = convgen.Module() // ok, blank identifier is harmless
 
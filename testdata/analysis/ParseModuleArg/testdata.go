@@ -33,3 +33,8 @@ var C5 = convgen.Struct[struct{}, struct{ e int }](
 var C6 = convgen.Struct[struct{}, struct{ f int }](
 	(asis(convgen.Module())), // want `module must be convgen.Module\(\) or package-level variable`
 )
+
+var (
+	_ = convgen.Module(convgen.ImportModule(mod2)) // want `cannot import module "mod2" of the same package`
+	_ = convgen.Module(convgen.ImportModule(nil))  // want `module must be package-level variable of another package`
+)
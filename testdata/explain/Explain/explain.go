@@ -0,0 +1,35 @@
+//go:build convgen
+
+package testdata
+
+import (
+	"strconv"
+
+	"github.com/sublee/convgen"
+)
+
+type (
+	User struct {
+		ID       int
+		UserName string
+		Home     Address
+		Scores   []int32
+	}
+	Address struct{ City string }
+)
+
+type (
+	Person struct {
+		ID     string
+		Name   string
+		Home   Place
+		Scores []int64
+	}
+	Place struct{ City string }
+)
+
+var mod = convgen.Module(convgen.ImportFunc(strconv.Itoa))
+
+var convUser = convgen.Struct[User, Person](mod, // want `explain convUser: User -> Person\n\tok: ID +-> ID +// function strconv.Itoa\n\tok: UserName \[Name\] -> Name +// assignment\n\tok: Home +-> Home +// subconverter convgen_mod_Address_Place\n\tok: Scores +-> Scores // loop over elements, basic cast to int64\n\n\tconvgen_mod_Address_Place: Address -> Place\n\tok: Home.City \[City\] -> Home.City \[City\] // assignment`
+	convgen.RenameTrimPrefix("User", ""),
+)
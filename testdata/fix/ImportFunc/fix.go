@@ -0,0 +1,18 @@
+//go:build convgen
+
+package testdata
+
+import (
+	"github.com/sublee/convgen"
+)
+
+type (
+	X struct{ N string }
+	Y struct{ N int }
+)
+
+var mod = convgen.Module()
+
+var XToY = convgen.Struct[X, Y](nil) // want `cannot convert X.N \(string\) to Y.N \(int\)`
+
+var YToX = convgen.Struct[Y, X](mod) // want `cannot convert Y.N \(int\) to X.N \(string\)`
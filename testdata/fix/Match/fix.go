@@ -0,0 +1,19 @@
+//go:build convgen
+
+package testdata
+
+import (
+	"github.com/sublee/convgen"
+)
+
+type (
+	User struct {
+		EMail string
+		Zip   string
+	}
+	Person struct {
+		Email string
+	}
+)
+
+var UserToPerson = convgen.Struct[User, Person](nil) // want `invalid match between User and Person`
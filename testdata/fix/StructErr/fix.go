@@ -0,0 +1,18 @@
+//go:build convgen
+
+package testdata
+
+import (
+	"strconv"
+
+	"github.com/sublee/convgen"
+)
+
+type (
+	X struct{ N string }
+	Y struct{ N int }
+)
+
+var mod = convgen.Module(convgen.ImportFuncErr(strconv.Atoi))
+
+var XToY = convgen.Struct[X, Y](mod) // want `cannot call strconv.Atoi to convert`
@@ -0,0 +1,78 @@
+//go:build convgen
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sublee/convgen"
+)
+
+func Ptr[T any](v T) *T { return &v }
+
+func Deref[T any](p *T) (T, error) {
+	if p == nil {
+		var zero T
+		return zero, errors.New("nil pointer")
+	}
+	return *p, nil
+}
+
+type Opt[T any] struct {
+	V  T
+	OK bool
+}
+
+func Some[T any](v T) Opt[T] { return Opt[T]{v, true} }
+
+func durationPtr(d time.Duration) *time.Duration {
+	d = d.Round(time.Second)
+	return &d
+}
+
+var mod = convgen.Module(
+	convgen.ImportFuncGeneric(Ptr[any]),
+	convgen.ImportFuncGeneric(Some[any]),
+	convgen.ImportFuncGenericErr(Deref[any]),
+	convgen.ImportFunc(durationPtr), // takes precedence over Ptr
+)
+
+type (
+	User struct {
+		Name    string
+		Age     int
+		Tags    []string
+		Timeout time.Duration
+	}
+	UserView struct {
+		Name    *string
+		Age     Opt[int]
+		Tags    *[]string
+		Timeout *time.Duration
+	}
+	UserInput struct {
+		Name    *string
+		Age     *int
+		Tags    *[]string
+		Timeout *time.Duration
+	}
+)
+
+var (
+	EncodeUser = convgen.Struct[User, UserView](mod)
+	DecodeUser = convgen.StructErr[UserInput, User](mod)
+)
+
+func main() {
+	v := EncodeUser(User{"Alice", 42, []string{"admin"}, 1500 * time.Millisecond})
+	fmt.Println(*v.Name, v.Age, *v.Tags, *v.Timeout)
+
+	name, age, tags, timeout := "Bob", 7, []string{"guest"}, time.Second
+	u, err := DecodeUser(UserInput{&name, &age, &tags, &timeout})
+	fmt.Println(u, err)
+
+	_, err = DecodeUser(UserInput{Name: &name})
+	fmt.Println(err)
+}
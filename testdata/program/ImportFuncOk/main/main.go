@@ -0,0 +1,92 @@
+//go:build convgen
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sublee/convgen"
+	"github.com/sublee/convgen/pkg/convgenerrors"
+)
+
+type Country int
+
+const (
+	CountryUnknown Country = iota
+	CountryKR
+	CountryUS
+)
+
+func ParseCountry(code string) (Country, bool) {
+	switch strings.ToUpper(code) {
+	case "KR":
+		return CountryKR, true
+	case "US":
+		return CountryUS, true
+	}
+	return CountryUnknown, false
+}
+
+type Level int
+
+func ParseLevel(s string) (Level, bool) {
+	switch s {
+	case "low":
+		return 1, true
+	case "high":
+		return 9, true
+	}
+	return -1, false // not zero on purpose
+}
+
+var (
+	dec     = convgen.Module(convgen.ImportFuncOk(ParseCountry))
+	decOr   = convgen.Module(convgen.ImportFuncOkOr(ParseCountry, CountryUS))
+	decZero = convgen.Module(convgen.ImportFuncOk(ParseCountry))
+	decLvl  = convgen.Module(convgen.ImportFuncOk(ParseLevel))
+)
+
+type (
+	AddressInput struct {
+		Country string
+	}
+	UserInput struct {
+		Name    string
+		Country string
+		Address AddressInput
+	}
+	Address struct {
+		Country Country
+	}
+	User struct {
+		Name    string
+		Country Country
+		Address Address
+	}
+	TaskInput struct{ Level string }
+	Task      struct{ Level Level }
+)
+
+var (
+	DecodeUser      = convgen.StructErr[UserInput, User](dec)
+	DecodeUserLoose = convgen.Struct[UserInput, User](decZero)
+	DecodeUserOr    = convgen.StructErr[UserInput, User](decOr)
+	DecodeTaskLoose = convgen.Struct[TaskInput, Task](decLvl)
+)
+
+func main() {
+	in := UserInput{"Alice", "kr", AddressInput{"us"}}
+	fmt.Println(DecodeUser(in))
+
+	in = UserInput{"Bob", "kr", AddressInput{"jp"}}
+	u, err := DecodeUser(in)
+	fmt.Println(u, err, errors.Is(err, convgenerrors.ErrNotOk))
+
+	in = UserInput{"Carol", "xx", AddressInput{"jp"}}
+	fmt.Println(DecodeUserLoose(in))
+	fmt.Println(DecodeUserOr(in))
+
+	fmt.Println(DecodeTaskLoose(TaskInput{"high"}), DecodeTaskLoose(TaskInput{"mid"}))
+}
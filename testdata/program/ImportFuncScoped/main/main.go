@@ -0,0 +1,55 @@
+//go:build convgen
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/sublee/convgen"
+)
+
+func zeroPadded(n int) string { return fmt.Sprintf("%06d", n) }
+
+var mod = convgen.Module(
+	convgen.ImportFunc(strconv.Itoa),
+)
+
+type (
+	Item struct {
+		ID    int
+		Owner Owner
+	}
+	Owner struct {
+		ID int
+	}
+	ItemView struct {
+		ID    string
+		Owner OwnerView
+	}
+	OwnerView struct {
+		ID string
+	}
+	ItemSummary struct {
+		Owner OwnerView
+	}
+)
+
+var (
+	// EncodeItem formats every int including the nested owner ID as zero-padded
+	// code.
+	EncodeItem = convgen.Struct[Item, ItemView](mod,
+		convgen.ImportFunc(zeroPadded),
+	)
+	// SummarizeItem follows the module, so its implicit subconverter for the
+	// owner is not affected by zeroPadded.
+	SummarizeItem = convgen.Struct[Item, ItemSummary](mod,
+		convgen.MatchSkip(Item{}.ID, nil),
+	)
+)
+
+func main() {
+	item := Item{ID: 42, Owner: Owner{ID: 7}}
+	fmt.Println(EncodeItem(item))
+	fmt.Println(SummarizeItem(item))
+}
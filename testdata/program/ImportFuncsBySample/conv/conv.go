@@ -0,0 +1,49 @@
+package conv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Cents int64
+
+// FormatCents converts cents to a decimal string.
+func FormatCents(c Cents) string { return fmt.Sprintf("%d.%02d", c/100, c%100) }
+
+// ParseCents converts a decimal string to cents.
+func ParseCents(s string) (Cents, error) {
+	whole, frac, _ := strings.Cut(s, ".")
+	n, err := strconv.ParseInt(whole+frac, 10, 64)
+	return Cents(n), err
+}
+
+// FormatTime formats a time in RFC 3339.
+func FormatTime(t time.Time) string { return t.UTC().Format(time.RFC3339) }
+
+// ParseTime parses a time in RFC 3339.
+func ParseTime(s string) (time.Time, error) { return time.Parse(time.RFC3339, s) }
+
+// Not imported: no output.
+func Validate(c Cents) error { return nil }
+
+// Not imported: output parameter. Functions of the same signature do not
+// conflict.
+func WriteCents(c Cents, b *strings.Builder) error {
+	_, err := b.WriteString(FormatCents(c))
+	return err
+}
+func WriteCentsRounded(c Cents, b *strings.Builder) error {
+	_, err := b.WriteString(FormatCents(c / 100 * 100))
+	return err
+}
+
+// Not imported: variadic.
+func Join(ss ...string) string { return strings.Join(ss, ",") }
+
+// Not imported: generic.
+func Ptr[T any](v T) *T { return &v }
+
+// Not imported: unexported.
+func itoa(i int) string { return strconv.Itoa(i) }
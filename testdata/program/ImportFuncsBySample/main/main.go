@@ -0,0 +1,41 @@
+//go:build convgen
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sublee/convgen"
+
+	"example.com/ImportFuncsBySample/conv"
+)
+
+var mod = convgen.Module(convgen.ImportFuncsBySample(conv.Cents(0)))
+
+type (
+	Order struct {
+		Price     conv.Cents
+		CreatedAt time.Time
+	}
+	OrderView struct {
+		Price     string
+		CreatedAt string
+	}
+)
+
+var (
+	EncodeOrder = convgen.Struct[Order, OrderView](mod)
+	DecodeOrder = convgen.StructErr[OrderView, Order](mod)
+)
+
+func main() {
+	v := EncodeOrder(Order{1250, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)})
+	fmt.Println(v)
+
+	o, err := DecodeOrder(v)
+	fmt.Println(o.Price, o.CreatedAt.Format(time.DateTime), err)
+
+	_, err = DecodeOrder(OrderView{"x", v.CreatedAt})
+	fmt.Println(err)
+}
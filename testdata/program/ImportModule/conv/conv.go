@@ -0,0 +1,30 @@
+//go:build convgen
+
+package conv
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/sublee/convgen"
+)
+
+type (
+	Money struct {
+		Amount   int
+		Currency string
+	}
+	MoneyView struct {
+		Amount   string
+		Currency string
+	}
+)
+
+var Mod = convgen.Module(convgen.ImportFunc(strconv.Itoa), convgen.ImportFuncErr(strconv.Atoi))
+
+// The converter-scoped functions tell whether the converters are called or
+// new subconverters are generated.
+var (
+	EncodeMoney = convgen.Struct[Money, MoneyView](Mod, convgen.ImportFunc(strings.ToUpper))
+	DecodeMoney = convgen.StructErr[MoneyView, Money](Mod, convgen.ImportFunc(strings.ToLower))
+)
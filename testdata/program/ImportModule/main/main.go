@@ -0,0 +1,41 @@
+//go:build convgen
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/sublee/convgen"
+
+	"example.com/ImportModule/conv"
+)
+
+type (
+	Order struct {
+		ID    string
+		Price conv.Money
+	}
+	OrderView struct {
+		ID    string
+		Price conv.MoneyView
+	}
+)
+
+var mod = convgen.Module(convgen.ImportModule(conv.Mod))
+
+var (
+	EncodeOrder = convgen.Struct[Order, OrderView](mod)
+	DecodeOrder = convgen.StructErr[OrderView, Order](mod)
+)
+
+func main() {
+	// Output: {o1 {42 USD}}
+	view := EncodeOrder(Order{ID: "o1", Price: conv.Money{Amount: 42, Currency: "usd"}})
+	fmt.Println(view)
+
+	// Output: {o1 {42 usd}} <nil>
+	fmt.Println(DecodeOrder(view))
+
+	// Output: { {0 }} converting OrderView.Price.Amount: strconv.Atoi: parsing "NaN": invalid syntax
+	fmt.Println(DecodeOrder(OrderView{Price: conv.MoneyView{Amount: "NaN"}}))
+}
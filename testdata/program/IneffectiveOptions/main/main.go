@@ -0,0 +1,39 @@
+//go:build convgen
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/sublee/convgen"
+)
+
+type User struct {
+	ID   int
+	Name string
+}
+
+func (u User) Nickname() string { return u.Name }
+
+type Person struct {
+	ID   int
+	Name string
+}
+
+// Every option below takes no effect. Convgen should warn about them but
+// still generate the converter.
+var mod = convgen.Module(
+	convgen.ImportFunc(strconv.Itoa),
+	convgen.RenameReplace("Foo", "Bar", "Foo", "Bar"),
+	convgen.DiscoverGetters("Fetch", ""),
+	convgen.ForUnion(convgen.RenameToLower(true, true)),
+)
+
+var UserToPerson = convgen.Struct[User, Person](mod,
+	convgen.RenameTrimPrefix("User", ""),
+)
+
+func main() {
+	fmt.Println(UserToPerson(User{ID: 1, Name: "Alice"}))
+}
@@ -0,0 +1,120 @@
+//go:build convgen
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/sublee/convgen"
+)
+
+func wrapErr(err error) error {
+	return fmt.Errorf("base: %w", err)
+}
+
+func formatID(id int) string {
+	return "#" + strconv.Itoa(id)
+}
+
+type (
+	Role     int
+	RoleView string
+)
+
+const (
+	RoleUnknown Role = iota
+	RoleAdmin
+)
+
+const (
+	RoleViewUnknown RoleView = "unknown"
+	RoleViewAdmin   RoleView = "admin"
+)
+
+type (
+	User struct {
+		UserID   int
+		UserName string
+		Role     Role
+	}
+	APIUser struct {
+		ID   string
+		Name string
+		Role RoleView
+	}
+	DBUser struct {
+		UserID   string
+		UserName string
+		Role     RoleView
+	}
+)
+
+type (
+	Item struct {
+		ItemID   int
+		ItemName string
+	}
+	ItemView struct {
+		Id   string
+		Name string
+	}
+)
+
+var (
+	base = convgen.Module(
+		convgen.RenameTrimPrefix("User", "User"),
+		convgen.ImportFunc(strconv.Itoa),
+		convgen.ImportFuncErr(strconv.Atoi),
+		convgen.ImportErrWrap(wrapErr),
+	)
+
+	// Inherits all options of base but overrides strconv.Itoa.
+	api = convgen.Module(convgen.Extend(base),
+		convgen.ImportFunc(formatID),
+	)
+
+	// Resets the renaming rule and the error wrapper of base.
+	db = convgen.Module(convgen.Extend(base),
+		convgen.RenameReset(true, true),
+		convgen.ImportErrWrapReset(),
+	)
+
+	itemBase = convgen.Module(
+		convgen.ImportFunc(strconv.Itoa),
+		convgen.ForStruct(convgen.RenameTrimPrefix("Item", "")),
+	)
+
+	// Replaces the functions of itemBase and extends its renaming rule for
+	// struct converters.
+	itemAPI = convgen.Module(convgen.Extend(itemBase),
+		convgen.ImportFuncReset(),
+		convgen.ImportFunc(formatID),
+		convgen.ForStruct(convgen.RenameToLower(true, true)),
+	)
+)
+
+var (
+	// Visible in api and db because it is declared with base.
+	EncodeRole = convgen.Enum[Role, RoleView](base, RoleViewUnknown, convgen.RenameTrimPrefix("Role", "RoleView"))
+
+	EncodeAPIUser = convgen.Struct[User, APIUser](api)
+	EncodeDBUser  = convgen.Struct[User, DBUser](db)
+	DecodeAPIUser = convgen.StructErr[APIUser, User](api, convgen.MatchSkip(APIUser{}.Role, User{}.Role))
+	DecodeDBUser  = convgen.StructErr[DBUser, User](db, convgen.MatchSkip(DBUser{}.Role, User{}.Role))
+
+	EncodeItem = convgen.Struct[Item, ItemView](itemAPI)
+)
+
+func main() {
+	user := User{UserID: 42, UserName: "Alice", Role: RoleAdmin}
+	fmt.Println(EncodeAPIUser(user))
+	fmt.Println(EncodeDBUser(user))
+
+	_, err := DecodeAPIUser(APIUser{ID: "x"})
+	fmt.Println(err)
+	_, err = DecodeDBUser(DBUser{UserID: "x"})
+	fmt.Println(err)
+
+	fmt.Println(EncodeItem(Item{ItemID: 7, ItemName: "Pen"}))
+}
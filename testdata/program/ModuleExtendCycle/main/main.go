@@ -0,0 +1,14 @@
+//go:build convgen
+
+package main
+
+import "github.com/sublee/convgen"
+
+// Modules cannot extend each other. Go reports the initialization cycle before
+// Convgen parses the modules.
+var (
+	a = convgen.Module(convgen.Extend(b))
+	b = convgen.Module(convgen.Extend(a))
+)
+
+func main() {}
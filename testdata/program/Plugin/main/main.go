@@ -0,0 +1,58 @@
+//go:build convgen
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/sublee/convgen"
+)
+
+// Optional and Decimal are converted by the plugins registered in the test.
+type (
+	Optional[T any] struct {
+		Value T
+		Valid bool
+	}
+	Decimal string
+)
+
+type (
+	User struct {
+		Name Optional[string]
+		Age  Optional[int32]
+	}
+	UserView struct {
+		Name string
+		Age  int64
+	}
+
+	Price     struct{ Amount Decimal }
+	PriceView struct{ Amount float64 }
+
+	Coupon     struct{ Rate Optional[Decimal] }
+	CouponView struct{ Rate float64 }
+)
+
+var (
+	EncodeUser   = convgen.Struct[User, UserView](nil)
+	EncodePrice  = convgen.StructErr[Price, PriceView](nil)
+	EncodeCoupon = convgen.StructErr[Coupon, CouponView](nil)
+)
+
+func main() {
+	// Output: {Name:Alice Age:0}
+	fmt.Printf("%+v\n", EncodeUser(User{Name: Optional[string]{"Alice", true}}))
+
+	// Output: {Amount:9.99} <nil>
+	p, err := EncodePrice(Price{"9.99"})
+	fmt.Printf("%+v %v\n", p, err)
+
+	// Output: converting Price.Amount: strconv.ParseFloat: parsing "free": invalid syntax
+	_, err = EncodePrice(Price{"free"})
+	fmt.Println(err)
+
+	// Output: converting Coupon.Rate: strconv.ParseFloat: parsing "half": invalid syntax
+	_, err = EncodeCoupon(Coupon{Optional[Decimal]{"half", true}})
+	fmt.Println(err)
+}
@@ -0,0 +1,25 @@
+//go:build convgen
+
+package main
+
+import (
+	"github.com/sublee/convgen"
+)
+
+// Decimal is converted by the plugin registered in the test.
+type Decimal string
+
+type (
+	Price     struct{ Amount Decimal }
+	PriceView struct{ Amount float64 }
+)
+
+var EncodePrice = convgen.Struct[Price, PriceView](nil)
+
+func main() {
+	// The plugin requires an error return but convgen.Struct cannot return
+	// it.
+	EncodePrice(Price{"9.99"})
+
+	panic("convgen will fail")
+}
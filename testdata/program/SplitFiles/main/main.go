@@ -0,0 +1,24 @@
+package main
+
+import "fmt"
+
+type (
+	XX struct{ I int }
+	YY struct{ I string }
+)
+
+type (
+	X1 struct{ Sub XX }
+	Y1 struct{ Sub YY }
+	X2 struct{ Sub XX }
+	Y2 struct{ Sub YY }
+)
+
+func main() {
+	// Output: 1 2
+	fmt.Println(XtoY1(X1{Sub: XX{I: 1}}).Sub.I)
+	fmt.Println(XtoY2(X2{Sub: XX{I: 2}}).Sub.I)
+
+	// Output: HELLO
+	fmt.Println(shout("hello"))
+}
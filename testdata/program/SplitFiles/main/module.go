@@ -0,0 +1,13 @@
+//go:build convgen
+
+package main
+
+import (
+	"strconv"
+
+	"github.com/sublee/convgen"
+)
+
+var mod = convgen.Module(
+	convgen.ImportFunc(strconv.Itoa),
+)
@@ -0,0 +1,15 @@
+//go:build convgen
+
+package main
+
+import (
+	"strings"
+
+	"github.com/sublee/convgen"
+)
+
+var XtoY2 = convgen.Struct[X2, Y2](mod)
+
+func shout(s string) string {
+	return strings.ToUpper(s)
+}
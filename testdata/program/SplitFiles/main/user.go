@@ -0,0 +1,9 @@
+//go:build convgen
+
+package main
+
+import "github.com/sublee/convgen"
+
+// XtoY1 and XtoY2 share the XX -> YY subconv. It is generated once in the
+// package file rather than in each file.
+var XtoY1 = convgen.Struct[X1, Y1](mod)
@@ -0,0 +1,15 @@
+//go:build convgen
+
+package main
+
+import "github.com/sublee/convgen"
+
+type (
+	X  struct{ Child XX }
+	Y  struct{ Child YY }
+	XX struct{ N int }
+	YY struct{ N int }
+)
+
+// convgen_gen.go would hold both XtoY and the XX -> YY subconv.
+var XtoY = convgen.Struct[X, Y](nil)
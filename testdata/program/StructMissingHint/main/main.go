@@ -0,0 +1,28 @@
+//go:build convgen
+
+package main
+
+import (
+	"github.com/sublee/convgen"
+)
+
+type User struct {
+	Adress     string
+	EMail      string
+	StatusCode string
+	Zip        string
+}
+
+type Person struct {
+	Address   string
+	Email     string
+	StateCode string
+	Phone     string
+}
+
+// Convgen should suggest likely intended pairs for missing fields.
+var UserToPerson = convgen.Struct[User, Person](nil)
+
+func main() {
+	panic("convgen will fail")
+}